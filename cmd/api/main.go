@@ -10,6 +10,7 @@ import (
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 	"github.com/mauFade/playzy/internal/http/routes"
+	"github.com/mauFade/playzy/internal/repository"
 	"github.com/rs/cors"
 )
 
@@ -39,13 +40,17 @@ func main() {
 	}
 	defer db.Close()
 
-	router := routes.Router(db)
+	if err := repository.Migrate(db); err != nil {
+		log.Fatal("Error migrating db:", err)
+	}
+
+	router := routes.Router(db, connStr)
 
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:3000"},
 		AllowCredentials: true,
 		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		Debug:            true,
 	})
 
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns a cursor into the opaque token handed to clients.
+func EncodeCursor(c MessageCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by EncodeCursor.
+func DecodeCursor(token string) (*MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	u, err := uuid.Parse(id)
+
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &MessageCursor{CreatedAt: t, ID: u}, nil
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboxEntry is a conversation of the inbox with its last message. Partner
+// fields are only set for direct conversations, Name only for groups.
+type InboxEntry struct {
+	ConversationID      uuid.UUID  `json:"conversationId"`
+	Kind                string     `json:"kind"`
+	Name                string     `json:"name,omitempty"`
+	PartnerID           *uuid.UUID `json:"partnerId,omitempty"`
+	PartnerGamertag     string     `json:"partnerGamertag,omitempty"`
+	LastMessageID       uuid.UUID  `json:"lastMessageId"`
+	LastMessagePreview  string     `json:"lastMessagePreview"`
+	LastMessageSenderID uuid.UUID  `json:"lastMessageSenderId"`
+	LastMessageAt       time.Time  `json:"lastMessageAt"`
+	UnreadCount         int        `json:"unreadCount"`
+	// LastMessageReactions aggregates the reactions to the last message.
+	LastMessageReactions []ReactionCount `json:"lastMessageReactions,omitempty"`
+}
+
+type InboxPageResponse struct {
+	Page          int          `json:"page"`
+	TotalPages    int          `json:"totalPages"`
+	Conversations []InboxEntry `json:"conversations"`
+}
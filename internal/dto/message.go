@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageCursor is a position in a conversation. Messages are ordered by
+// (created_at, id) so the position stays stable while new messages arrive.
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// MessagePage selects which slice of a conversation to read. Before and After
+// are mutually exclusive; with neither set the latest messages are returned.
+type MessagePage struct {
+	Before *MessageCursor
+	After  *MessageCursor
+	Limit  int
+}
@@ -0,0 +1,8 @@
+package dto
+
+// ReactionCount aggregates the reactions a message got with one emoji.
+type ReactionCount struct {
+	Emoji   string   `json:"emoji"`
+	Count   int      `json:"count"`
+	UserIDs []string `json:"userIds"`
+}
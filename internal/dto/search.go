@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageSearchHit is a message matching a search. Snippet is an HTML-escaped
+// excerpt of the content with the matched words wrapped in <mark> tags.
+type MessageSearchHit struct {
+	ID             uuid.UUID `json:"id"`
+	SenderID       string    `json:"senderId"`
+	ReceiverID     string    `json:"receiverId,omitempty"`
+	ConversationID string    `json:"conversationId,omitempty"`
+	Snippet        string    `json:"snippet"`
+	Timestamp      time.Time `json:"timestamp"`
+	IsMine         bool      `json:"isMine"`
+}
+
+type MessageSearchResponse struct {
+	Results []MessageSearchHit `json:"results"`
+	// Before loads older results; it is empty once there are no more.
+	Before string `json:"before,omitempty"`
+}
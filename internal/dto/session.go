@@ -7,17 +7,34 @@ import (
 )
 
 type SessionWithUser struct {
-	ID           uuid.UUID `json:"id"`
-	Game         string    `json:"game"`
-	UserID       uuid.UUID `json:"user_id"`
-	Objective    string    `json:"objetive"`
-	Rank         *string   `json:"rank"`
-	IsRanked     bool      `json:"is_ranked"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	CreatedAt    time.Time `json:"created_at"`
-	UserName     string    `json:"user_name"`
-	UserGamertag string    `json:"user_gamertag"`
-	Email        string    `json:"email"`
+	ID           uuid.UUID  `json:"id"`
+	Game         string     `json:"game"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Objective    string     `json:"objetive"`
+	Rank         *string    `json:"rank"`
+	IsRanked     bool       `json:"is_ranked"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UserName     string     `json:"user_name"`
+	UserGamertag string     `json:"user_gamertag"`
+	Email        string     `json:"email"`
+	MaxPlayers   int        `json:"max_players"`
+	Status       string     `json:"status"`
+	Players      int        `json:"players"`
+	StartsAt     *time.Time `json:"starts_at"`
+	TimeZone     string     `json:"time_zone"`
+}
+
+// SessionFilter narrows the sessions listed by FindAvailable. Full sessions
+// are left out unless IncludeFull is set. StartsFrom and StartsUntil bound
+// when sessions start; unscheduled ones start when they were posted.
+type SessionFilter struct {
+	Page        int
+	Rank        string
+	Game        string
+	IncludeFull bool
+	StartsFrom  *time.Time
+	StartsUntil *time.Time
 }
 
 type SessionsPageResponse struct {
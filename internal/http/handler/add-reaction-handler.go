@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/mauFade/playzy/internal/websocket"
+)
+
+type AddReactionHandler struct {
+	db       *sql.DB
+	notifier Notifier
+}
+
+type addReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+func NewAddReactionHandler(d *sql.DB, n Notifier) *AddReactionHandler {
+	return &AddReactionHandler{
+		db:       d,
+		notifier: n,
+	}
+}
+
+func (h *AddReactionHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	var body addReactionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "invalid request body"})
+
+		return
+	}
+
+	uc := message.NewAddReactionUseCase(repository.NewMessageRepository(h.db), repository.NewReactionRepository(h.db), repository.NewConversationRepository(h.db))
+
+	change, err := uc.Execute(&message.ReactionRequest{
+		UserID:    userID,
+		MessageID: r.PathValue("id"),
+		Emoji:     body.Emoji,
+	})
+
+	if err != nil {
+		w.WriteHeader(messageChangeStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	if change.Changed {
+		if err := h.notifier.Notify(change.Participants, websocket.EventReactionAdded, change); err != nil {
+			log.Printf("Erro ao notificar reação na mensagem %s: %v", change.MessageID, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(change)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
+)
+
+type CreateConversationHandler struct {
+	db *sql.DB
+}
+
+type createConversationRequest struct {
+	Name      string   `json:"name"`
+	MemberIDs []string `json:"member_ids"`
+}
+
+func NewCreateConversationHandler(d *sql.DB) *CreateConversationHandler {
+	return &CreateConversationHandler{
+		db: d,
+	}
+}
+
+func (h *CreateConversationHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	var body createConversationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "invalid request body"})
+
+		return
+	}
+
+	uc := conversation.NewCreateGroupConversationUseCase(repository.NewConversationRepository(h.db))
+
+	created, err := uc.Execute(&conversation.CreateGroupConversationRequest{
+		CreatorID: userID,
+		Name:      body.Name,
+		MemberIDs: body.MemberIDs,
+	})
+
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		if errors.Is(err, conversation.ErrMissingName) || errors.Is(err, conversation.ErrNameTooLong) || errors.Is(err, conversation.ErrInvalidMemberID) {
+			status = http.StatusBadRequest
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/mauFade/playzy/internal/constants"
 	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
 	"github.com/mauFade/playzy/internal/usecase/session"
 )
 
@@ -19,6 +20,11 @@ type createSessionRequest struct {
 	Objective string  `json:"objective"`
 	Rank      *string `json:"rank"`
 	IsRanked  bool    `json:"is_ranked"`
+	// MaxPlayers is optional; the use case picks a default
+	MaxPlayers int `json:"max_players"`
+	// StartsAt may carry an offset or be a local time in TimeZone
+	StartsAt string `json:"starts_at"`
+	TimeZone string `json:"time_zone"`
 }
 
 func NewCreateSessionHandler(d *sql.DB) *CreateSessionHandler {
@@ -36,7 +42,12 @@ func (h *CreateSessionHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	decoder.Decode(&req)
+	if err := decoder.Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "invalid request body"})
+
+		return
+	}
 
 	if req.Game == "" || req.Objective == "" {
 		w.WriteHeader(http.StatusBadRequest)
@@ -48,18 +59,23 @@ func (h *CreateSessionHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	sr := repository.NewSessionRepository(h.db)
 	ur := repository.NewUserRepository(h.db)
 
-	usecase := session.NewCreateSessionUseCase(sr, ur)
+	lobby := conversation.NewSessionLobby(repository.NewConversationRepository(h.db))
+
+	usecase := session.NewCreateSessionUseCase(sr, ur, lobby)
 
 	response, err := usecase.Execute(&session.CreateSessionRequest{
-		UserID:    userID,
-		Game:      req.Game,
-		Objective: req.Objective,
-		Rank:      req.Rank,
-		IsRanked:  req.IsRanked,
+		UserID:     userID,
+		Game:       req.Game,
+		Objective:  req.Objective,
+		Rank:       req.Rank,
+		IsRanked:   req.IsRanked,
+		MaxPlayers: req.MaxPlayers,
+		StartsAt:   req.StartsAt,
+		TimeZone:   req.TimeZone,
 	})
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(sessionErrorStatus(err))
 		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
 
 		return
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/mauFade/playzy/internal/websocket"
+)
+
+type DeleteMessageHandler struct {
+	db       *sql.DB
+	notifier Notifier
+}
+
+func NewDeleteMessageHandler(d *sql.DB, n Notifier) *DeleteMessageHandler {
+	return &DeleteMessageHandler{
+		db:       d,
+		notifier: n,
+	}
+}
+
+func (h *DeleteMessageHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	uc := message.NewDeleteMessageUseCase(repository.NewMessageRepository(h.db), repository.NewConversationRepository(h.db))
+
+	deleted, err := uc.Execute(&message.DeleteMessageRequest{
+		UserID:    userID,
+		MessageID: r.PathValue("id"),
+	})
+
+	if err != nil {
+		w.WriteHeader(messageChangeStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	if deleted.Changed {
+		if err := h.notifier.Notify(deleted.Participants, websocket.EventMessageDeleted, deleted.Message); err != nil {
+			log.Printf("Erro ao notificar exclusão da mensagem %s: %v", deleted.Message.ID, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deleted.Message)
+}
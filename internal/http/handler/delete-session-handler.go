@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
+	"github.com/mauFade/playzy/internal/usecase/session"
+)
+
+type DeleteSessionHandler struct {
+	db *sql.DB
+}
+
+func NewDeleteSessionHandler(d *sql.DB) *DeleteSessionHandler {
+	return &DeleteSessionHandler{
+		db: d,
+	}
+}
+
+func (h *DeleteSessionHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	lobby := conversation.NewSessionLobby(repository.NewConversationRepository(h.db))
+
+	uc := session.NewDeleteSessionUseCase(repository.NewSessionRepository(h.db), lobby)
+
+	err := uc.Execute(&session.DeleteSessionRequest{
+		UserID:    userID,
+		SessionID: r.PathValue("id"),
+	})
+
+	if err != nil {
+		w.WriteHeader(sessionErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "session deleted"})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/mauFade/playzy/internal/websocket"
+)
+
+type EditMessageHandler struct {
+	db       *sql.DB
+	notifier Notifier
+}
+
+type editMessageRequest struct {
+	Content string `json:"content"`
+}
+
+func NewEditMessageHandler(d *sql.DB, n Notifier) *EditMessageHandler {
+	return &EditMessageHandler{
+		db:       d,
+		notifier: n,
+	}
+}
+
+func (h *EditMessageHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	var body editMessageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "invalid request body"})
+
+		return
+	}
+
+	uc := message.NewEditMessageUseCase(repository.NewMessageRepository(h.db), repository.NewConversationRepository(h.db))
+
+	edited, err := uc.Execute(&message.EditMessageRequest{
+		UserID:    userID,
+		MessageID: r.PathValue("id"),
+		Content:   body.Content,
+	})
+
+	if err != nil {
+		w.WriteHeader(messageChangeStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	if edited.Changed {
+		if err := h.notifier.Notify(edited.Participants, websocket.EventMessageEdited, edited.Message); err != nil {
+			log.Printf("Erro ao notificar edição da mensagem %s: %v", edited.Message.ID, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(edited.Message)
+}
+
+// messageChangeStatus maps failures to change a message, or its reactions, to
+// HTTP status codes.
+func messageChangeStatus(err error) int {
+	switch {
+	case errors.Is(err, message.ErrInvalidMessageID), errors.Is(err, message.ErrEmptyContent), errors.Is(err, message.ErrInvalidEmoji):
+		return http.StatusBadRequest
+	case errors.Is(err, message.ErrMessageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, message.ErrNotMessageSender), errors.Is(err, message.ErrEditWindowExpired):
+		return http.StatusForbidden
+	case errors.Is(err, message.ErrMessageDeleted):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/storage"
+	"github.com/mauFade/playzy/internal/usecase/message"
+)
+
+type GetAttachmentHandler struct {
+	db        *sql.DB
+	store     storage.BlobStore
+	thumbnail bool
+}
+
+// NewGetAttachmentHandler serves attachment files, or their thumbnails when
+// thumbnail is set.
+func NewGetAttachmentHandler(d *sql.DB, s storage.BlobStore, thumbnail bool) *GetAttachmentHandler {
+	return &GetAttachmentHandler{
+		db:        d,
+		store:     s,
+		thumbnail: thumbnail,
+	}
+}
+
+func (h *GetAttachmentHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	uc := message.NewGetAttachmentUseCase(
+		repository.NewAttachmentRepository(h.db),
+		repository.NewMessageRepository(h.db),
+		repository.NewConversationRepository(h.db),
+		h.store,
+	)
+
+	content, err := uc.Execute(&message.GetAttachmentRequest{
+		UserID:       userID,
+		AttachmentID: r.PathValue("id"),
+		Thumbnail:    h.thumbnail,
+	})
+
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(attachmentStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	defer content.Body.Close()
+
+	// Only images are shown inline; anything else is downloaded so uploaded
+	// files never run in the page
+	disposition := "attachment"
+	if content.Attachment.IsImage() {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", content.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": content.Attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+
+	if !h.thumbnail {
+		w.Header().Set("Content-Length", strconv.FormatInt(content.Attachment.Size, 10))
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, content.Body); err != nil {
+		log.Printf("Erro ao enviar anexo %s: %v", content.Attachment.ID, err)
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/user"
+)
+
+type GetUserPresenceHandler struct {
+	db       *sql.DB
+	presence user.PresenceReader
+}
+
+func NewGetUserPresenceHandler(d *sql.DB, p user.PresenceReader) *GetUserPresenceHandler {
+	return &GetUserPresenceHandler{
+		db:       d,
+		presence: p,
+	}
+}
+
+func (h *GetUserPresenceHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := r.PathValue("id")
+
+	uc := user.NewGetUsersPresenceUseCase(repository.NewUserRepository(h.db), h.presence)
+
+	presences, err := uc.Execute(&user.GetUsersPresenceRequest{
+		UserIDs: []string{id},
+	})
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	if len(presences) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "user not found with this id"})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(presences[0])
+}
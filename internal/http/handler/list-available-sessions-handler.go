@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/mauFade/playzy/internal/constants"
 	"github.com/mauFade/playzy/internal/repository"
 	"github.com/mauFade/playzy/internal/usecase/session"
 )
@@ -23,9 +25,43 @@ func NewListAvailableSessionsHandler(d *sql.DB) *ListAvailableSessionsHandler {
 func (h *ListAvailableSessionsHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	userID := r.Context().Value(constants.UserKey).(string)
+
 	page := r.URL.Query().Get("page")
 	rank := r.URL.Query().Get("rank")
 	game := r.URL.Query().Get("game")
+	// Full sessions are hidden unless asked for
+	includeFull, _ := strconv.ParseBool(r.URL.Query().Get("include_full"))
+
+	// starts_within=2h lists what starts in the next two hours, counted from
+	// starts_after when it is given
+	var startsWithin time.Duration
+	if value := r.URL.Query().Get("starts_within"); value != "" {
+		within, err := time.ParseDuration(value)
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"message": session.ErrInvalidTimeWindow.Error()})
+
+			return
+		}
+
+		startsWithin = within
+	}
+
+	var startsAfter *time.Time
+	if value := r.URL.Query().Get("starts_after"); value != "" {
+		after, err := time.Parse(time.RFC3339, value)
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"message": "starts_after must be an RFC 3339 time"})
+
+			return
+		}
+
+		startsAfter = &after
+	}
 
 	pNum, err := strconv.Atoi(page)
 
@@ -38,16 +74,20 @@ func (h *ListAvailableSessionsHandler) Handle(w http.ResponseWriter, r *http.Req
 
 	sr := repository.NewSessionRepository(h.db)
 
-	uc := session.NewListAvailableSessionsUseCase(sr)
+	uc := session.NewListAvailableSessionsUseCase(sr, repository.NewSessionMemberRepository(h.db))
 
 	resp, err := uc.Execute(&session.ListAvailableSessionsRequest{
-		Page: pNum,
-		Game: game,
-		Rank: rank,
+		UserID:       userID,
+		Page:         pNum,
+		Game:         game,
+		Rank:         rank,
+		IncludeFull:  includeFull,
+		StartsAfter:  startsAfter,
+		StartsWithin: startsWithin,
 	})
 
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(sessionErrorStatus(err))
 		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
 
 		return
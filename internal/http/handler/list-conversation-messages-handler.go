@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+)
+
+type ListConversationMessagesHandler struct {
+	db *sql.DB
+}
+
+func NewListConversationMessagesHandler(d *sql.DB) *ListConversationMessagesHandler {
+	return &ListConversationMessagesHandler{
+		db: d,
+	}
+}
+
+func (h *ListConversationMessagesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	uc := message.NewListConversationMessagesUseCase(
+		repository.NewMessageRepository(h.db),
+		repository.NewReactionRepository(h.db),
+		repository.NewConversationRepository(h.db),
+		repository.NewAttachmentRepository(h.db),
+	)
+
+	resp, err := uc.Execute(&message.ListConversationMessagesRequest{
+		UserID:         userID,
+		ConversationID: r.PathValue("id"),
+		Limit:          limit,
+		Before:         r.URL.Query().Get("before"),
+		After:          r.URL.Query().Get("after"),
+	})
+
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, message.ErrConversationNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, dto.ErrInvalidCursor), errors.Is(err, message.ErrConflictingCursors):
+			status = http.StatusBadRequest
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+)
+
+type ListInboxHandler struct {
+	db *sql.DB
+}
+
+func NewListInboxHandler(d *sql.DB) *ListInboxHandler {
+	return &ListInboxHandler{
+		db: d,
+	}
+}
+
+func (h *ListInboxHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	// page e limit são opcionais
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	mr := repository.NewMessageRepository(h.db)
+	uc := message.NewListInboxUseCase(mr, repository.NewReactionRepository(h.db))
+
+	resp, err := uc.Execute(&message.ListInboxRequest{
+		UserID: userID,
+		Page:   page,
+		Limit:  limit,
+	})
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
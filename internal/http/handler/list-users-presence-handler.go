@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/user"
+)
+
+type ListUsersPresenceHandler struct {
+	db       *sql.DB
+	presence user.PresenceReader
+}
+
+func NewListUsersPresenceHandler(d *sql.DB, p user.PresenceReader) *ListUsersPresenceHandler {
+	return &ListUsersPresenceHandler{
+		db:       d,
+		presence: p,
+	}
+}
+
+func (h *ListUsersPresenceHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// ids=<id>,<id>,...
+	var ids []string
+
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	uc := user.NewGetUsersPresenceUseCase(repository.NewUserRepository(h.db), h.presence)
+
+	presences, err := uc.Execute(&user.GetUsersPresenceRequest{
+		UserIDs: ids,
+	})
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(presences)
+}
@@ -0,0 +1,6 @@
+package handler
+
+// Notifier pushes real-time events to the connected clients of the given users.
+type Notifier interface {
+	Notify(userIDs []string, eventType string, payload any) error
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/mauFade/playzy/internal/websocket"
+)
+
+type RemoveReactionHandler struct {
+	db       *sql.DB
+	notifier Notifier
+}
+
+func NewRemoveReactionHandler(d *sql.DB, n Notifier) *RemoveReactionHandler {
+	return &RemoveReactionHandler{
+		db:       d,
+		notifier: n,
+	}
+}
+
+func (h *RemoveReactionHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	uc := message.NewRemoveReactionUseCase(repository.NewMessageRepository(h.db), repository.NewReactionRepository(h.db), repository.NewConversationRepository(h.db))
+
+	// O emoji vem URL-encoded no caminho
+	change, err := uc.Execute(&message.ReactionRequest{
+		UserID:    userID,
+		MessageID: r.PathValue("id"),
+		Emoji:     r.PathValue("emoji"),
+	})
+
+	if err != nil {
+		w.WriteHeader(messageChangeStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	if change.Changed {
+		if err := h.notifier.Notify(change.Participants, websocket.EventReactionRemoved, change); err != nil {
+			log.Printf("Erro ao notificar reação removida da mensagem %s: %v", change.MessageID, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(change)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
+	"github.com/mauFade/playzy/internal/usecase/session"
+)
+
+type RemoveSessionMemberHandler struct {
+	db *sql.DB
+}
+
+func NewRemoveSessionMemberHandler(d *sql.DB) *RemoveSessionMemberHandler {
+	return &RemoveSessionMemberHandler{
+		db: d,
+	}
+}
+
+// Handle kicks the player in the userId path value, or makes the user leave
+// when the route has none.
+func (h *RemoveSessionMemberHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	memberID := r.PathValue("userId")
+	if memberID == "" {
+		memberID = userID
+	}
+
+	lobby := conversation.NewSessionLobby(repository.NewConversationRepository(h.db))
+
+	uc := session.NewRemoveSessionMemberUseCase(repository.NewSessionRepository(h.db), repository.NewSessionMemberRepository(h.db), lobby)
+
+	err := uc.Execute(&session.RemoveSessionMemberRequest{
+		UserID:    userID,
+		SessionID: r.PathValue("id"),
+		MemberID:  memberID,
+	})
+
+	if err != nil {
+		w.WriteHeader(sessionErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "removed from session"})
+}
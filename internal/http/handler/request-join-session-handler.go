@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/session"
+)
+
+type RequestJoinSessionHandler struct {
+	db *sql.DB
+}
+
+func NewRequestJoinSessionHandler(d *sql.DB) *RequestJoinSessionHandler {
+	return &RequestJoinSessionHandler{
+		db: d,
+	}
+}
+
+func (h *RequestJoinSessionHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	uc := session.NewRequestJoinSessionUseCase(repository.NewSessionRepository(h.db), repository.NewSessionMemberRepository(h.db))
+
+	member, err := uc.Execute(&session.RequestJoinSessionRequest{
+		UserID:    userID,
+		SessionID: r.PathValue("id"),
+	})
+
+	if err != nil {
+		w.WriteHeader(sessionErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+}
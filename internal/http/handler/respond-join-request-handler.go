@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
+	"github.com/mauFade/playzy/internal/usecase/session"
+)
+
+type RespondJoinRequestHandler struct {
+	db     *sql.DB
+	accept bool
+}
+
+// NewRespondJoinRequestHandler answers join requests, accepting them when
+// accept is set and declining them otherwise.
+func NewRespondJoinRequestHandler(d *sql.DB, accept bool) *RespondJoinRequestHandler {
+	return &RespondJoinRequestHandler{
+		db:     d,
+		accept: accept,
+	}
+}
+
+func (h *RespondJoinRequestHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	lobby := conversation.NewSessionLobby(repository.NewConversationRepository(h.db))
+
+	uc := session.NewRespondJoinRequestUseCase(repository.NewSessionRepository(h.db), repository.NewSessionMemberRepository(h.db), lobby)
+
+	member, err := uc.Execute(&session.RespondJoinRequestRequest{
+		UserID:    userID,
+		SessionID: r.PathValue("id"),
+		MemberID:  r.PathValue("userId"),
+		Accept:    h.accept,
+	})
+
+	if err != nil {
+		w.WriteHeader(sessionErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+)
+
+type SearchMessagesHandler struct {
+	db *sql.DB
+}
+
+func NewSearchMessagesHandler(d *sql.DB) *SearchMessagesHandler {
+	return &SearchMessagesHandler{
+		db: d,
+	}
+}
+
+func (h *SearchMessagesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	uc := message.NewSearchMessagesUseCase(repository.NewMessageRepository(h.db))
+
+	resp, err := uc.Execute(&message.SearchMessagesRequest{
+		UserID: userID,
+		Query:  r.URL.Query().Get("q"),
+		Limit:  limit,
+		Before: r.URL.Query().Get("before"),
+	})
+
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		if errors.Is(err, message.ErrInvalidSearchQuery) || errors.Is(err, dto.ErrInvalidCursor) {
+			status = http.StatusBadRequest
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/usecase/session"
+)
+
+// sessionErrorStatus maps failures of the session use cases to HTTP status
+// codes.
+func sessionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, session.ErrInvalidSessionID), errors.Is(err, session.ErrInvalidUserID),
+		errors.Is(err, session.ErrInvalidStatus), errors.Is(err, session.ErrMissingFields),
+		errors.Is(err, session.ErrInvalidMaxPlayers), errors.Is(err, session.ErrInvalidTimeZone),
+		errors.Is(err, session.ErrInvalidStartsAt), errors.Is(err, session.ErrStartsInPast),
+		errors.Is(err, session.ErrStartsTooFar), errors.Is(err, session.ErrInvalidTimeWindow),
+		errors.Is(err, session.ErrRankRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, session.ErrSessionNotFound), errors.Is(err, session.ErrMemberNotFound),
+		errors.Is(err, session.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, session.ErrNotSessionOwner), errors.Is(err, session.ErrJoinRefused):
+		return http.StatusForbidden
+	case errors.Is(err, session.ErrOwnerCannotJoin), errors.Is(err, session.ErrOwnerCannotLeave),
+		errors.Is(err, session.ErrAlreadyRequested), errors.Is(err, session.ErrRequestNotPending),
+		errors.Is(err, session.ErrSessionFull), errors.Is(err, session.ErrSessionNotActive),
+		errors.Is(err, session.ErrInvalidTransition), errors.Is(err, session.ErrRosterTooLarge),
+		errors.Is(err, session.ErrSessionOver):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
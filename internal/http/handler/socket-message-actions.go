@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/mauFade/playzy/internal/websocket"
+)
+
+// SocketMessageActions runs the message use cases for events received over
+// the websocket, so the socket doesn't depend on them.
+type SocketMessageActions struct {
+	db *sql.DB
+}
+
+func NewSocketMessageActions(d *sql.DB) *SocketMessageActions {
+	return &SocketMessageActions{
+		db: d,
+	}
+}
+
+func (a *SocketMessageActions) Edit(userID, messageID, content string) (*websocket.MessageUpdate, error) {
+	uc := message.NewEditMessageUseCase(repository.NewMessageRepository(a.db), repository.NewConversationRepository(a.db))
+
+	edited, err := uc.Execute(&message.EditMessageRequest{
+		UserID:    userID,
+		MessageID: messageID,
+		Content:   content,
+	})
+
+	if err != nil {
+		return nil, socketMessageError(err)
+	}
+
+	return &websocket.MessageUpdate{Payload: edited.Message, Participants: edited.Participants, Changed: edited.Changed}, nil
+}
+
+func (a *SocketMessageActions) Delete(userID, messageID string) (*websocket.MessageUpdate, error) {
+	uc := message.NewDeleteMessageUseCase(repository.NewMessageRepository(a.db), repository.NewConversationRepository(a.db))
+
+	deleted, err := uc.Execute(&message.DeleteMessageRequest{
+		UserID:    userID,
+		MessageID: messageID,
+	})
+
+	if err != nil {
+		return nil, socketMessageError(err)
+	}
+
+	return &websocket.MessageUpdate{Payload: deleted.Message, Participants: deleted.Participants, Changed: deleted.Changed}, nil
+}
+
+func (a *SocketMessageActions) AddReaction(userID, messageID, emoji string) (*websocket.MessageUpdate, error) {
+	uc := message.NewAddReactionUseCase(repository.NewMessageRepository(a.db), repository.NewReactionRepository(a.db), repository.NewConversationRepository(a.db))
+
+	return reactionUpdate(uc.Execute(&message.ReactionRequest{UserID: userID, MessageID: messageID, Emoji: emoji}))
+}
+
+func (a *SocketMessageActions) RemoveReaction(userID, messageID, emoji string) (*websocket.MessageUpdate, error) {
+	uc := message.NewRemoveReactionUseCase(repository.NewMessageRepository(a.db), repository.NewReactionRepository(a.db), repository.NewConversationRepository(a.db))
+
+	return reactionUpdate(uc.Execute(&message.ReactionRequest{UserID: userID, MessageID: messageID, Emoji: emoji}))
+}
+
+func reactionUpdate(change *message.ReactionChange, err error) (*websocket.MessageUpdate, error) {
+	if err != nil {
+		return nil, socketMessageError(err)
+	}
+
+	return &websocket.MessageUpdate{Payload: change, Participants: change.Participants, Changed: change.Changed}, nil
+}
+
+// socketMessageError maps failures to change a message to the error codes
+// sent back over the socket, following messageChangeStatus.
+func socketMessageError(err error) error {
+	switch messageChangeStatus(err) {
+	case http.StatusBadRequest:
+		return websocket.NewHandlerError(websocket.ErrCodeInvalidPayload, err.Error())
+	case http.StatusNotFound:
+		return websocket.NewHandlerError(websocket.ErrCodeNotFound, err.Error())
+	case http.StatusForbidden:
+		return websocket.NewHandlerError(websocket.ErrCodeForbidden, err.Error())
+	case http.StatusConflict:
+		return websocket.NewHandlerError(websocket.ErrCodeConflict, err.Error())
+	default:
+		return err
+	}
+}
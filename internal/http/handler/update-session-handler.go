@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/session"
+)
+
+type UpdateSessionHandler struct {
+	db *sql.DB
+}
+
+// updateSessionRequest only carries the fields the owner wants to change.
+type updateSessionRequest struct {
+	Game       *string `json:"game"`
+	Objective  *string `json:"objective"`
+	Rank       *string `json:"rank"`
+	IsRanked   *bool   `json:"is_ranked"`
+	MaxPlayers *int    `json:"max_players"`
+	StartsAt   *string `json:"starts_at"`
+	TimeZone   *string `json:"time_zone"`
+}
+
+func NewUpdateSessionHandler(d *sql.DB) *UpdateSessionHandler {
+	return &UpdateSessionHandler{
+		db: d,
+	}
+}
+
+func (h *UpdateSessionHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	var body updateSessionRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "invalid request body"})
+
+		return
+	}
+
+	uc := session.NewUpdateSessionUseCase(repository.NewSessionRepository(h.db))
+
+	s, err := uc.Execute(&session.UpdateSessionRequest{
+		UserID:     userID,
+		SessionID:  r.PathValue("id"),
+		Game:       body.Game,
+		Objective:  body.Objective,
+		Rank:       body.Rank,
+		IsRanked:   body.IsRanked,
+		MaxPlayers: body.MaxPlayers,
+		StartsAt:   body.StartsAt,
+		TimeZone:   body.TimeZone,
+	})
+
+	if err != nil {
+		w.WriteHeader(sessionErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(s)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
+	"github.com/mauFade/playzy/internal/usecase/session"
+)
+
+type UpdateSessionStatusHandler struct {
+	db *sql.DB
+}
+
+type updateSessionStatusRequest struct {
+	Status model.SessionStatus `json:"status"`
+}
+
+func NewUpdateSessionStatusHandler(d *sql.DB) *UpdateSessionStatusHandler {
+	return &UpdateSessionStatusHandler{
+		db: d,
+	}
+}
+
+func (h *UpdateSessionStatusHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	var body updateSessionStatusRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "invalid request body"})
+
+		return
+	}
+
+	lobby := conversation.NewSessionLobby(repository.NewConversationRepository(h.db))
+
+	uc := session.NewUpdateSessionStatusUseCase(repository.NewSessionRepository(h.db), lobby)
+
+	s, err := uc.Execute(&session.UpdateSessionStatusRequest{
+		UserID:    userID,
+		SessionID: r.PathValue("id"),
+		Status:    body.Status,
+	})
+
+	if err != nil {
+		w.WriteHeader(sessionErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(s)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/mauFade/playzy/internal/constants"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/storage"
+	"github.com/mauFade/playzy/internal/usecase/message"
+)
+
+// multipartOverhead is the room left for multipart headers and boundaries on
+// top of the file size limit.
+const multipartOverhead = 64 << 10
+
+type UploadAttachmentHandler struct {
+	db      *sql.DB
+	store   storage.BlobStore
+	maxSize int64
+}
+
+func NewUploadAttachmentHandler(d *sql.DB, s storage.BlobStore, maxSize int64) *UploadAttachmentHandler {
+	return &UploadAttachmentHandler{
+		db:      d,
+		store:   s,
+		maxSize: maxSize,
+	}
+}
+
+// Handle stores the file sent in the "file" field of a multipart form. The
+// returned attachment ID is then referenced by the message sent over the
+// websocket.
+func (h *UploadAttachmentHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.Context().Value(constants.UserKey).(string)
+
+	uc := message.NewUploadAttachmentUseCase(repository.NewAttachmentRepository(h.db), h.store, h.maxSize)
+
+	r.Body = http.MaxBytesReader(w, r.Body, uc.MaxSize()+multipartOverhead)
+
+	file, header, err := r.FormFile("file")
+
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+
+		if errors.As(err, &tooLarge) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(map[string]string{"message": message.ErrAttachmentTooLarge.Error()})
+
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": message.ErrEmptyAttachment.Error()})
+
+		return
+	}
+
+	defer file.Close()
+
+	// Read one byte past the limit so oversized files are told apart
+	data, err := io.ReadAll(io.LimitReader(file, uc.MaxSize()+1))
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "could not read file"})
+
+		return
+	}
+
+	attachment, err := uc.Execute(&message.UploadAttachmentRequest{
+		UploaderID: userID,
+		FileName:   header.Filename,
+		Data:       data,
+	})
+
+	if err != nil {
+		status := attachmentStatus(err)
+
+		if status == http.StatusInternalServerError {
+			log.Printf("Erro ao salvar anexo: %v", err)
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+func attachmentStatus(err error) int {
+	switch {
+	case errors.Is(err, message.ErrEmptyAttachment):
+		return http.StatusBadRequest
+	case errors.Is(err, message.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, message.ErrUnsupportedFileType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, message.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
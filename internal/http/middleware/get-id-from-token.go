@@ -3,11 +3,21 @@ package middleware
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 func GetUserIDFromToken(tokenString string) (string, error) {
+	userID, _, err := ParseToken(tokenString)
+
+	return userID, err
+}
+
+// ParseToken validates a token signed with JWT_SECRET and returns the userID
+// claim along with the token expiration. The expiration is zero when the token
+// has no exp claim.
+func ParseToken(tokenString string) (string, time.Time, error) {
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 
@@ -19,13 +29,26 @@ func GetUserIDFromToken(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok || !token.Valid {
+		return "", time.Time{}, fmt.Errorf("invalid token or claims")
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := claims["userID"].(string)
-		return userID, nil
+	userID, ok := claims["userID"].(string)
+
+	if !ok || userID == "" {
+		return "", time.Time{}, fmt.Errorf("invalid userID type in token")
+	}
+
+	var expiresAt time.Time
+
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
 	}
 
-	return "", fmt.Errorf("invalid token or claims")
+	return userID, expiresAt, nil
 }
@@ -2,14 +2,24 @@ package routes
 
 import (
 	"database/sql"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/mauFade/playzy/internal/http/handler"
 	"github.com/mauFade/playzy/internal/http/middleware"
 	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/storage"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
+	"github.com/mauFade/playzy/internal/usecase/session"
 	"github.com/mauFade/playzy/internal/websocket"
 )
 
+// sessionExpiryInterval is how often stale sessions are looked for.
+const sessionExpiryInterval = time.Minute
+
 func ApplyMiddlewares(handler http.HandlerFunc, middlewares ...func(http.HandlerFunc) http.HandlerFunc) http.HandlerFunc {
 	for _, middleware := range middlewares {
 		handler = middleware(handler)
@@ -21,7 +31,9 @@ func CommonMiddlewares(handler http.HandlerFunc) http.HandlerFunc {
 	return ApplyMiddlewares(handler, middleware.LoggerMiddleware, middleware.EnsureAuthenticatedMiddleware)
 }
 
-func Router(db *sql.DB) *http.ServeMux {
+// Router wires every route. connStr is used by backends that need a dedicated
+// database connection, such as the Postgres pub/sub.
+func Router(db *sql.DB, connStr string) *http.ServeMux {
 	createUserHandler := handler.NewCreateUserHandler(db)
 	authHandler := handler.NewAuthenticateUserHandler(db)
 
@@ -29,7 +41,44 @@ func Router(db *sql.DB) *http.ServeMux {
 	listSessionsHandler := handler.NewListAvailableSessionsHandler(db)
 
 	messageRepo := repository.NewMessageRepository(db)
-	wsManager := websocket.NewManager(db, messageRepo)
+	userRepo := repository.NewUserRepository(db)
+	conversationRepo := repository.NewConversationRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	maxConnections, _ := strconv.Atoi(os.Getenv("WS_MAX_CONNECTIONS_PER_USER"))
+
+	// Instances share websocket deliveries through Postgres when scaled out
+	var pubsub websocket.PubSub = websocket.NewMemoryPubSub()
+	if os.Getenv("WS_PUBSUB") == "postgres" {
+		pgPubSub, err := websocket.NewPostgresPubSub(db, connStr)
+		if err != nil {
+			log.Fatal("Error starting websocket pub/sub:", err)
+		}
+		pubsub = pgPubSub
+	}
+
+	limits := websocket.DefaultRateLimits()
+	if rate, err := strconv.ParseFloat(os.Getenv("WS_MESSAGES_PER_SECOND"), 64); err == nil && rate > 0 {
+		limits.Messages.Rate = rate
+	}
+	if burst, _ := strconv.Atoi(os.Getenv("WS_MESSAGE_BURST")); burst > 0 {
+		limits.Messages.Burst = burst
+	}
+	if muteAfter, err := strconv.Atoi(os.Getenv("WS_MUTE_AFTER_VIOLATIONS")); err == nil {
+		limits.MuteAfter = muteAfter
+	}
+	if muteSeconds, _ := strconv.Atoi(os.Getenv("WS_MUTE_SECONDS")); muteSeconds > 0 {
+		limits.MuteFor = time.Duration(muteSeconds) * time.Second
+	}
+
+	wsManager := websocket.NewManager(db, messageRepo,
+		websocket.WithPubSub(pubsub),
+		websocket.WithRateLimits(limits),
+		websocket.WithMaxConnectionsPerUser(maxConnections),
+		websocket.WithUserRepository(userRepo),
+		websocket.WithMessageActions(handler.NewSocketMessageActions(db)),
+		websocket.WithConversationRepository(conversationRepo),
+		websocket.WithAttachmentRepository(attachmentRepo),
+	)
 	go wsManager.Start()
 
 	router := http.NewServeMux()
@@ -37,14 +86,86 @@ func Router(db *sql.DB) *http.ServeMux {
 	router.HandleFunc("POST /users", middleware.LoggerMiddleware(createUserHandler.Handle))
 	router.HandleFunc("POST /auth", middleware.LoggerMiddleware(authHandler.Handle))
 
+	userPresenceHandler := handler.NewGetUserPresenceHandler(db, wsManager)
+	usersPresenceHandler := handler.NewListUsersPresenceHandler(db, wsManager)
+	router.HandleFunc("GET /users/presence", CommonMiddlewares(usersPresenceHandler.Handle))
+	router.HandleFunc("GET /users/{id}/presence", CommonMiddlewares(userPresenceHandler.Handle))
+
 	router.HandleFunc("POST /sessions", CommonMiddlewares(createSessionHandler.Handle))
 	router.HandleFunc("GET /sessions", CommonMiddlewares(listSessionsHandler.Handle))
 
+	updateSessionHandler := handler.NewUpdateSessionHandler(db)
+	deleteSessionHandler := handler.NewDeleteSessionHandler(db)
+	router.HandleFunc("PATCH /sessions/{id}", CommonMiddlewares(updateSessionHandler.Handle))
+	router.HandleFunc("DELETE /sessions/{id}", CommonMiddlewares(deleteSessionHandler.Handle))
+
+	requestJoinSessionHandler := handler.NewRequestJoinSessionHandler(db)
+	acceptJoinRequestHandler := handler.NewRespondJoinRequestHandler(db, true)
+	declineJoinRequestHandler := handler.NewRespondJoinRequestHandler(db, false)
+	removeSessionMemberHandler := handler.NewRemoveSessionMemberHandler(db)
+	router.HandleFunc("POST /sessions/{id}/join", CommonMiddlewares(requestJoinSessionHandler.Handle))
+	router.HandleFunc("POST /sessions/{id}/leave", CommonMiddlewares(removeSessionMemberHandler.Handle))
+	router.HandleFunc("POST /sessions/{id}/members/{userId}/accept", CommonMiddlewares(acceptJoinRequestHandler.Handle))
+	router.HandleFunc("POST /sessions/{id}/members/{userId}/decline", CommonMiddlewares(declineJoinRequestHandler.Handle))
+	router.HandleFunc("DELETE /sessions/{id}/members/{userId}", CommonMiddlewares(removeSessionMemberHandler.Handle))
+
+	updateSessionStatusHandler := handler.NewUpdateSessionStatusHandler(db)
+	router.HandleFunc("PATCH /sessions/{id}/status", CommonMiddlewares(updateSessionStatusHandler.Handle))
+
+	// Sessions nobody closes stop being listed after SESSION_TTL_MINUTES
+	sessionTTLMinutes, _ := strconv.Atoi(os.Getenv("SESSION_TTL_MINUTES"))
+	expireSessions := session.NewExpireSessionsUseCase(
+		repository.NewSessionRepository(db),
+		conversation.NewSessionLobby(conversationRepo),
+		time.Duration(sessionTTLMinutes)*time.Minute,
+	)
+	go expireSessions.Run(sessionExpiryInterval, nil)
+
 	router.HandleFunc("GET /ws", middleware.LoggerMiddleware(wsManager.ServeWs))
-	router.HandleFunc("GET /conversations", CommonMiddlewares(wsManager.GetConversationHandler))
 
 	listUsersMessagesHandler := handler.NewListUsersMessagesHandler(db)
+	router.HandleFunc("GET /conversations", CommonMiddlewares(listUsersMessagesHandler.Handle))
 	router.HandleFunc("GET /messages", CommonMiddlewares(listUsersMessagesHandler.Handle))
 
+	searchMessagesHandler := handler.NewSearchMessagesHandler(db)
+	router.HandleFunc("GET /messages/search", CommonMiddlewares(searchMessagesHandler.Handle))
+
+	createConversationHandler := handler.NewCreateConversationHandler(db)
+	router.HandleFunc("POST /conversations", CommonMiddlewares(createConversationHandler.Handle))
+
+	listConversationMessagesHandler := handler.NewListConversationMessagesHandler(db)
+	router.HandleFunc("GET /conversations/{id}/messages", CommonMiddlewares(listConversationMessagesHandler.Handle))
+
+	editMessageHandler := handler.NewEditMessageHandler(db, wsManager)
+	deleteMessageHandler := handler.NewDeleteMessageHandler(db, wsManager)
+	router.HandleFunc("PATCH /messages/{id}", CommonMiddlewares(editMessageHandler.Handle))
+	router.HandleFunc("DELETE /messages/{id}", CommonMiddlewares(deleteMessageHandler.Handle))
+
+	addReactionHandler := handler.NewAddReactionHandler(db, wsManager)
+	removeReactionHandler := handler.NewRemoveReactionHandler(db, wsManager)
+	router.HandleFunc("POST /messages/{id}/reactions", CommonMiddlewares(addReactionHandler.Handle))
+	router.HandleFunc("DELETE /messages/{id}/reactions/{emoji}", CommonMiddlewares(removeReactionHandler.Handle))
+
+	attachmentsDir := os.Getenv("ATTACHMENTS_DIR")
+	if attachmentsDir == "" {
+		attachmentsDir = "uploads"
+	}
+
+	blobStore, err := storage.NewFileSystemStore(attachmentsDir)
+	if err != nil {
+		log.Fatal("Error opening attachments storage:", err)
+	}
+
+	maxAttachmentSize, _ := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_BYTES"), 10, 64)
+	uploadAttachmentHandler := handler.NewUploadAttachmentHandler(db, blobStore, maxAttachmentSize)
+	getAttachmentHandler := handler.NewGetAttachmentHandler(db, blobStore, false)
+	getAttachmentThumbnailHandler := handler.NewGetAttachmentHandler(db, blobStore, true)
+	router.HandleFunc("POST /attachments", CommonMiddlewares(uploadAttachmentHandler.Handle))
+	router.HandleFunc("GET /attachments/{id}", CommonMiddlewares(getAttachmentHandler.Handle))
+	router.HandleFunc("GET /attachments/{id}/thumbnail", CommonMiddlewares(getAttachmentThumbnailHandler.Handle))
+
+	listInboxHandler := handler.NewListInboxHandler(db)
+	router.HandleFunc("GET /inbox", CommonMiddlewares(listInboxHandler.Handle))
+
 	return router
 }
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a file uploaded to be shared in a message. It is uploaded on
+// its own first and then referenced by a single message of its uploader.
+type Attachment struct {
+	ID         uuid.UUID `json:"id"`
+	UploaderID string    `json:"uploaderId"`
+	// MessageID is the message the file was shared in; empty until sent.
+	MessageID   string `json:"messageId,omitempty"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	// Width and Height are the dimensions of images, zero for other files.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// StorageKey and ThumbnailKey locate the file and its thumbnail in the
+	// blob store. Images that couldn't be decoded have no thumbnail.
+	StorageKey   string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	HasThumbnail bool      `json:"hasThumbnail"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func (a *Attachment) GetID() uuid.UUID {
+	return a.ID
+}
+
+func (a *Attachment) GetUploaderID() string {
+	return a.UploaderID
+}
+
+func (a *Attachment) GetMessageID() string {
+	return a.MessageID
+}
+
+// IsSent reports whether the attachment was already shared in a message.
+func (a *Attachment) IsSent() bool {
+	return a.MessageID != ""
+}
+
+// IsImage reports whether the file is an image, which browsers may display
+// inline.
+func (a *Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
+
+// SetThumbnailKey records where the thumbnail was stored.
+func (a *Attachment) SetThumbnailKey(key string) {
+	a.ThumbnailKey = key
+	a.HasThumbnail = key != ""
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConversationKind tells one-to-one chats apart from group chats.
+type ConversationKind string
+
+const (
+	ConversationDirect ConversationKind = "direct"
+	ConversationGroup  ConversationKind = "group"
+)
+
+// Conversation groups the messages exchanged by its members. Direct
+// conversations always have exactly two members, or one when users write to
+// themselves.
+type Conversation struct {
+	ID        uuid.UUID        `json:"id"`
+	Kind      ConversationKind `json:"kind"`
+	Name      string           `json:"name,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+	// SessionID links the lobby chat of an LFG session to it.
+	SessionID string `json:"sessionId,omitempty"`
+	// ArchivedAt is set once the conversation is read-only.
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+}
+
+func NewGroupConversation(name string) *Conversation {
+	return &Conversation{
+		Kind: ConversationGroup,
+		Name: name,
+	}
+}
+
+func (c *Conversation) GetID() uuid.UUID {
+	return c.ID
+}
+
+func (c *Conversation) GetKind() ConversationKind {
+	return c.Kind
+}
+
+func (c *Conversation) GetName() string {
+	return c.Name
+}
+
+func (c *Conversation) IsGroup() bool {
+	return c.Kind == ConversationGroup
+}
+
+func (c *Conversation) GetSessionID() string {
+	return c.SessionID
+}
+
+func (c *Conversation) IsArchived() bool {
+	return c.ArchivedAt != nil
+}
@@ -10,9 +10,31 @@ type Message struct {
 	ID         uuid.UUID `json:"id"`
 	Content    string    `json:"content"`
 	SenderID   string    `json:"senderId"`
-	ReceiverID string    `json:"receiverId"`
+	ReceiverID string    `json:"receiverId,omitempty"`
 	Timestamp  time.Time `json:"timestamp"`
 	IsRead     bool      `json:"isRead"`
+	// ClientID is the ID chosen by the sending client so retries of the same
+	// message are stored only once.
+	ClientID string `json:"clientId,omitempty"`
+	// ReadAt is when the receiver read the message; nil while unread.
+	ReadAt *time.Time `json:"readAt,omitempty"`
+	// DeliveredAt is when the message first reached one of the receiver's
+	// connections; nil while they have been offline since it was sent.
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+	// EditedAt is when the content was last edited; nil if never edited.
+	EditedAt *time.Time `json:"editedAt,omitempty"`
+	// DeletedAt is set when the message was deleted for everyone. The row is
+	// kept as a tombstone with its content cleared.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// ReplyToID is the ID of the message this one answers, if any. It always
+	// belongs to the same conversation.
+	ReplyToID string `json:"replyToId,omitempty"`
+	// ConversationID is the conversation the message belongs to. Group
+	// messages are addressed to it and have no ReceiverID.
+	ConversationID string `json:"conversationId,omitempty"`
+	// Attachments are the files shared in the message. Senders only fill in
+	// the ID of files they uploaded beforehand.
+	Attachments []Attachment `json:"attachments,omitempty"`
 }
 
 func NewMessage(id uuid.UUID,
@@ -74,3 +96,121 @@ func (m *Message) GetIsRead() bool {
 func (m *Message) SetIsRead(isRead bool) {
 	m.IsRead = isRead
 }
+
+func (m *Message) GetClientID() string {
+	return m.ClientID
+}
+
+func (m *Message) SetClientID(clientID string) {
+	m.ClientID = clientID
+}
+
+func (m *Message) GetReadAt() *time.Time {
+	return m.ReadAt
+}
+
+func (m *Message) GetDeliveredAt() *time.Time {
+	return m.DeliveredAt
+}
+
+// IsDelivered reports whether the message reached its receiver.
+func (m *Message) IsDelivered() bool {
+	return m.DeliveredAt != nil
+}
+
+// MarkRead flags the message as read at the given time.
+func (m *Message) MarkRead(at time.Time) {
+	m.IsRead = true
+	m.ReadAt = &at
+}
+
+func (m *Message) GetReplyToID() string {
+	return m.ReplyToID
+}
+
+func (m *Message) SetReplyToID(replyToID string) {
+	m.ReplyToID = replyToID
+}
+
+func (m *Message) GetConversationID() string {
+	return m.ConversationID
+}
+
+// AttachmentIDs returns the IDs of the files shared in the message.
+func (m *Message) AttachmentIDs() []string {
+	ids := make([]string, 0, len(m.Attachments))
+	for _, a := range m.Attachments {
+		ids = append(ids, a.ID.String())
+	}
+
+	return ids
+}
+
+// HasAttachments reports whether files were shared in the message.
+func (m *Message) HasAttachments() bool {
+	return len(m.Attachments) > 0
+}
+
+// IsGroup reports whether the message was sent to a group conversation rather
+// than to a single receiver.
+func (m *Message) IsGroup() bool {
+	return m.ReceiverID == "" && m.ConversationID != ""
+}
+
+// IsReply reports whether the message answers another one.
+func (m *Message) IsReply() bool {
+	return m.ReplyToID != ""
+}
+
+// BelongsTo reports whether both users are the participants of the message's
+// conversation.
+func (m *Message) BelongsTo(userID, otherUserID string) bool {
+	return (m.SenderID == userID && m.ReceiverID == otherUserID) ||
+		(m.SenderID == otherUserID && m.ReceiverID == userID)
+}
+
+// InConversationOf reports whether both messages belong to the same
+// conversation.
+func (m *Message) InConversationOf(other *Message) bool {
+	if m.IsGroup() || other.IsGroup() {
+		return m.ConversationID == other.ConversationID
+	}
+
+	return other.BelongsTo(m.SenderID, m.ReceiverID)
+}
+
+func (m *Message) GetEditedAt() *time.Time {
+	return m.EditedAt
+}
+
+func (m *Message) GetDeletedAt() *time.Time {
+	return m.DeletedAt
+}
+
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// Edit replaces the content and records when it happened.
+func (m *Message) Edit(content string, at time.Time) {
+	m.Content = content
+	m.EditedAt = &at
+}
+
+// Tombstone clears the content and flags the message as deleted.
+func (m *Message) Tombstone(at time.Time) {
+	m.Content = ""
+	m.Attachments = nil
+	m.DeletedAt = &at
+}
+
+// Participants returns the distinct IDs of the users in a one-to-one
+// conversation. Members of group conversations are kept in
+// conversation_members instead.
+func (m *Message) Participants() []string {
+	if m.SenderID == m.ReceiverID {
+		return []string{m.SenderID}
+	}
+
+	return []string{m.SenderID, m.ReceiverID}
+}
@@ -0,0 +1,61 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessage(t *testing.T) {
+	id := uuid.New()
+	timestamp := time.Now()
+
+	message := model.NewMessage(id, "gg", "sender", "receiver", timestamp, false)
+
+	assert.Equal(t, id, message.GetID())
+	assert.Equal(t, "gg", message.GetContent())
+	assert.Equal(t, "sender", message.GetSenderID())
+	assert.Equal(t, "receiver", message.GetReceiverID())
+	assert.WithinDuration(t, timestamp, message.GetTimestamp(), time.Second)
+	assert.False(t, message.GetIsRead())
+	assert.Nil(t, message.GetReadAt())
+}
+
+func TestMessageMarkRead(t *testing.T) {
+	message := &model.Message{}
+	readAt := time.Now()
+
+	message.MarkRead(readAt)
+
+	assert.True(t, message.GetIsRead())
+	assert.Equal(t, readAt, *message.GetReadAt())
+}
+
+func TestMessageEditAndTombstone(t *testing.T) {
+	message := &model.Message{Content: "gg", SenderID: "sender", ReceiverID: "receiver"}
+	editedAt := time.Now()
+
+	message.Edit("gg wp", editedAt)
+	assert.Equal(t, "gg wp", message.GetContent())
+	assert.Equal(t, &editedAt, message.GetEditedAt())
+	assert.False(t, message.IsDeleted())
+
+	deletedAt := time.Now()
+	message.Tombstone(deletedAt)
+	assert.Empty(t, message.GetContent())
+	assert.Equal(t, &deletedAt, message.GetDeletedAt())
+	assert.True(t, message.IsDeleted())
+	assert.Equal(t, []string{"sender", "receiver"}, message.Participants())
+}
+
+func TestMessageBelongsTo(t *testing.T) {
+	message := &model.Message{SenderID: "sender", ReceiverID: "receiver", ReplyToID: "parent"}
+
+	assert.True(t, message.IsReply())
+	assert.True(t, message.BelongsTo("sender", "receiver"))
+	assert.True(t, message.BelongsTo("receiver", "sender"))
+	assert.False(t, message.BelongsTo("sender", "someone"))
+}
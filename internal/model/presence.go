@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// PresenceStatus is the chat availability of a user.
+type PresenceStatus string
+
+const (
+	PresenceOnline  PresenceStatus = "online"
+	PresenceAway    PresenceStatus = "away"
+	PresenceOffline PresenceStatus = "offline"
+)
+
+type Presence struct {
+	UserID     string         `json:"userId"`
+	Status     PresenceStatus `json:"status"`
+	LastSeenAt *time.Time     `json:"lastSeenAt"`
+}
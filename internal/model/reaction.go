@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reaction is an emoji a user put on a message. A user can react to the same
+// message with several emojis, but only once with each.
+type Reaction struct {
+	MessageID uuid.UUID `json:"messageId"`
+	UserID    string    `json:"userId"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"createdAt"`
+}
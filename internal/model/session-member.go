@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionMemberStatus string
+
+const (
+	// MemberPending is a player who asked to join and waits for the owner.
+	MemberPending SessionMemberStatus = "pending"
+	// MemberAccepted is a player in the session.
+	MemberAccepted SessionMemberStatus = "accepted"
+	// MemberDeclined is a player whose request the owner turned down.
+	MemberDeclined SessionMemberStatus = "declined"
+	// MemberKicked is a player the owner removed from the session.
+	MemberKicked SessionMemberStatus = "kicked"
+)
+
+// SessionMember is a player's place in a session, from the join request until
+// they leave. The owner is not a member; they are the session's UserID.
+type SessionMember struct {
+	SessionID   uuid.UUID           `json:"session_id"`
+	UserID      uuid.UUID           `json:"user_id"`
+	Gamertag    string              `json:"gamertag,omitempty"`
+	Status      SessionMemberStatus `json:"status"`
+	RequestedAt time.Time           `json:"requested_at"`
+	RespondedAt *time.Time          `json:"responded_at,omitempty"`
+}
+
+func NewSessionMember(sessionID, userID uuid.UUID, requestedAt time.Time) *SessionMember {
+	return &SessionMember{
+		SessionID:   sessionID,
+		UserID:      userID,
+		Status:      MemberPending,
+		RequestedAt: requestedAt,
+	}
+}
+
+func (m *SessionMember) IsPending() bool {
+	return m.Status == MemberPending
+}
+
+func (m *SessionMember) IsAccepted() bool {
+	return m.Status == MemberAccepted
+}
+
+// IsBlocked reports whether the owner turned the player away, so they can't
+// ask to join again.
+func (m *SessionMember) IsBlocked() bool {
+	return m.Status == MemberDeclined || m.Status == MemberKicked
+}
@@ -1,11 +1,45 @@
 package model
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type SessionStatus string
+
+const (
+	SessionOpen       SessionStatus = "open"
+	SessionFull       SessionStatus = "full"
+	SessionInProgress SessionStatus = "in_progress"
+	SessionClosed     SessionStatus = "closed"
+	SessionExpired    SessionStatus = "expired"
+	SessionCancelled  SessionStatus = "cancelled"
+)
+
+// sessionTransitions lists where a session can go from each status. Open and
+// full follow the roster; closed, expired and cancelled are final.
+var sessionTransitions = map[SessionStatus][]SessionStatus{
+	SessionOpen:       {SessionFull, SessionInProgress, SessionClosed, SessionExpired, SessionCancelled},
+	SessionFull:       {SessionOpen, SessionInProgress, SessionClosed, SessionExpired, SessionCancelled},
+	SessionInProgress: {SessionClosed, SessionExpired},
+}
+
+func (s SessionStatus) IsValid() bool {
+	switch s {
+	case SessionOpen, SessionFull, SessionInProgress, SessionClosed, SessionExpired, SessionCancelled:
+		return true
+	}
+
+	return false
+}
+
+// IsFinal reports whether a session in this status is over for good.
+func (s SessionStatus) IsFinal() bool {
+	return s == SessionClosed || s == SessionExpired || s == SessionCancelled
+}
+
 type SessionModel struct {
 	ID        uuid.UUID `json:"id"`         // type:uuid
 	Game      string    `json:"game"`       // type:varchar
@@ -15,6 +49,17 @@ type SessionModel struct {
 	IsRanked  bool      `json:"is_ranked"`  // type:bool
 	UpdatedAt time.Time `json:"updated_at"` // type:timestamp
 	CreatedAt time.Time `json:"created_at"` // type:timestamp
+	// MaxPlayers is the size of the party, owner included
+	MaxPlayers int           `json:"max_players"` // type:integer
+	Status     SessionStatus `json:"status"`      // type:varchar
+	// StartsAt is when the game is planned, in UTC; nil means right away
+	StartsAt *time.Time `json:"starts_at"` // type:timestamptz nullable:true
+	// TimeZone is the owner's IANA zone, for showing StartsAt as they meant it
+	TimeZone string `json:"time_zone"` // type:varchar
+	// Players counts the accepted members; it is read from session_members
+	Players int `json:"players"`
+	// LobbyID is the lobby chat conversation; it lives in conversations.session_id
+	LobbyID string `json:"lobby_id,omitempty"`
 }
 
 func NewSessionModel(
@@ -29,6 +74,8 @@ func NewSessionModel(
 		IsRanked:  isRanked,
 		UpdatedAt: updatedAt,
 		CreatedAt: createdAt,
+		Status:    SessionOpen,
+		TimeZone:  "UTC",
 	}
 }
 
@@ -83,3 +130,84 @@ func (s *SessionModel) GetUpdatedAt() time.Time {
 func (s *SessionModel) GetCreatedAt() time.Time {
 	return s.CreatedAt
 }
+
+func (s *SessionModel) GetLobbyID() string {
+	return s.LobbyID
+}
+
+func (s *SessionModel) SetLobbyID(id string) {
+	s.LobbyID = id
+}
+
+func (s *SessionModel) GetMaxPlayers() int {
+	return s.MaxPlayers
+}
+
+func (s *SessionModel) SetMaxPlayers(n int) {
+	s.MaxPlayers = n
+}
+
+func (s *SessionModel) GetStatus() SessionStatus {
+	return s.Status
+}
+
+// OpenSlots is how many more players the owner can accept.
+func (s *SessionModel) OpenSlots() int {
+	return max(0, s.MaxPlayers-1-s.Players)
+}
+
+func (s *SessionModel) IsFull() bool {
+	return s.Status == SessionFull || s.OpenSlots() == 0
+}
+
+// IsActive reports whether the session is still looking for players.
+func (s *SessionModel) IsActive() bool {
+	return s.Status == SessionOpen || s.Status == SessionFull
+}
+
+func (s *SessionModel) CanTransitionTo(to SessionStatus) bool {
+	return slices.Contains(sessionTransitions[s.Status], to)
+}
+
+// TransitionTo moves the session to a new status, reporting false and leaving
+// it untouched when the state machine doesn't allow it.
+func (s *SessionModel) TransitionTo(to SessionStatus, at time.Time) bool {
+	if !s.CanTransitionTo(to) {
+		return false
+	}
+
+	s.Status = to
+	s.UpdatedAt = at
+
+	return true
+}
+
+func (s *SessionModel) GetStartsAt() *time.Time {
+	return s.StartsAt
+}
+
+// SetSchedule plans the session for a moment in the owner's time zone. A nil
+// startsAt means it starts right away.
+func (s *SessionModel) SetSchedule(startsAt *time.Time, timeZone string) {
+	if startsAt != nil {
+		utc := startsAt.UTC()
+		startsAt = &utc
+	}
+
+	s.StartsAt = startsAt
+	s.TimeZone = timeZone
+}
+
+func (s *SessionModel) GetTimeZone() string {
+	return s.TimeZone
+}
+
+// StartTime is when the session starts, or when it was posted if it isn't
+// scheduled.
+func (s *SessionModel) StartTime() time.Time {
+	if s.StartsAt != nil {
+		return *s.StartsAt
+	}
+
+	return s.CreatedAt
+}
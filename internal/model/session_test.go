@@ -60,3 +60,46 @@ func TestSessionSettersAndGetters(t *testing.T) {
 	assert.Equal(t, &rank, session.GetRank())
 	assert.Equal(t, false, session.GetIsRanked())
 }
+
+func TestSessionOpenSlots(t *testing.T) {
+	session := model.NewSessionModel(uuid.New(), uuid.New(), "VALORANT", "Serious play", nil, false, time.Now(), time.Now())
+	session.SetMaxPlayers(3)
+
+	assert.Equal(t, 2, session.OpenSlots())
+	assert.False(t, session.IsFull())
+
+	session.Players = 2
+
+	assert.Equal(t, 0, session.OpenSlots())
+	assert.True(t, session.IsFull())
+
+	// Shrinking the party below the roster never reports negative slots
+	session.SetMaxPlayers(2)
+	assert.Equal(t, 0, session.OpenSlots())
+}
+
+func TestSessionTransitions(t *testing.T) {
+	session := model.NewSessionModel(uuid.New(), uuid.New(), "VALORANT", "Serious play", nil, false, time.Now(), time.Now())
+
+	assert.True(t, session.IsActive())
+	assert.True(t, session.TransitionTo(model.SessionFull, time.Now()))
+	assert.True(t, session.TransitionTo(model.SessionInProgress, time.Now()))
+	assert.False(t, session.IsActive())
+
+	// A game in progress can't go back to looking for players
+	assert.False(t, session.TransitionTo(model.SessionOpen, time.Now()))
+	assert.Equal(t, model.SessionInProgress, session.GetStatus())
+
+	assert.True(t, session.TransitionTo(model.SessionClosed, time.Now()))
+	assert.True(t, session.GetStatus().IsFinal())
+
+	for _, to := range []model.SessionStatus{model.SessionOpen, model.SessionFull, model.SessionInProgress, model.SessionExpired, model.SessionCancelled} {
+		assert.False(t, session.CanTransitionTo(to), to)
+	}
+}
+
+func TestSessionStatusIsValid(t *testing.T) {
+	assert.True(t, model.SessionCancelled.IsValid())
+	assert.False(t, model.SessionStatus("paused").IsValid())
+	assert.False(t, model.SessionStatus("").IsValid())
+}
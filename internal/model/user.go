@@ -18,6 +18,8 @@ type UserModel struct {
 	DeletedAt *time.Time `json:"deleted_at"` // type:timestamp nullable:true
 	UpdatedAt time.Time  `json:"updated_at"` // type:timestamp
 	CreatedAt time.Time  `json:"created_at"` // type:timestamp
+	// LastSeenAt is when the user was last connected to the chat.
+	LastSeenAt *time.Time `json:"last_seen_at"` // type:timestamp nullable:true
 }
 
 func NewUserModel(
@@ -117,3 +119,11 @@ func (u *UserModel) GetCreatedAt() time.Time {
 func (u *UserModel) GetUpdatedAt() time.Time {
 	return u.UpdatedAt
 }
+
+func (u *UserModel) GetLastSeenAt() *time.Time {
+	return u.LastSeenAt
+}
+
+func (u *UserModel) SetLastSeenAt(at time.Time) {
+	u.LastSeenAt = &at
+}
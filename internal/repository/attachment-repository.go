@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/mauFade/playzy/internal/model"
+)
+
+type AttachmentRepositoryInterface interface {
+	Create(a *model.Attachment) error
+	FindByID(id string) (*model.Attachment, error)
+	ListByMessages(messageIDs []string) (map[string][]model.Attachment, error)
+}
+
+// ErrAttachmentUnavailable is returned when a message shares a file that
+// belongs to another user or was already sent.
+var ErrAttachmentUnavailable = errors.New("attachment not found")
+
+// attachmentColumns lists the columns read by scanAttachment, in order.
+const attachmentColumns = `id, uploader_id, COALESCE(message_id::text, ''), file_name, content_type, size, width, height, storage_key, COALESCE(thumbnail_key, ''), created_at`
+
+func scanAttachment(row rowScanner) (*model.Attachment, error) {
+	var a model.Attachment
+	var thumbnailKey string
+
+	err := row.Scan(
+		&a.ID,
+		&a.UploaderID,
+		&a.MessageID,
+		&a.FileName,
+		&a.ContentType,
+		&a.Size,
+		&a.Width,
+		&a.Height,
+		&a.StorageKey,
+		&thumbnailKey,
+		&a.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.SetThumbnailKey(thumbnailKey)
+
+	return &a, nil
+}
+
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(d *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{
+		db: d,
+	}
+}
+
+// Create stores the attachment with the ID chosen by the caller, which is also
+// part of its storage key.
+func (r *AttachmentRepository) Create(a *model.Attachment) error {
+	var thumbnailKey sql.NullString
+
+	if a.ThumbnailKey != "" {
+		thumbnailKey = sql.NullString{String: a.ThumbnailKey, Valid: true}
+	}
+
+	return r.db.QueryRow(`
+		INSERT INTO attachments (id, uploader_id, file_name, content_type, size, width, height, storage_key, thumbnail_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, a.ID, a.UploaderID, a.FileName, a.ContentType, a.Size, a.Width, a.Height, a.StorageKey, thumbnailKey).Scan(&a.CreatedAt)
+}
+
+func (r *AttachmentRepository) FindByID(id string) (*model.Attachment, error) {
+	a, err := scanAttachment(r.db.QueryRow(`SELECT `+attachmentColumns+` FROM attachments WHERE id = $1`, id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return a, err
+}
+
+// attachToMessage links the uploader's attachments that weren't sent yet to
+// the message, as part of saving it, and returns them. It fails with
+// ErrAttachmentUnavailable when any of them belongs to another user or was
+// already sent.
+func attachToMessage(tx *sql.Tx, messageID, uploaderID string, ids []string) ([]model.Attachment, error) {
+	rows, err := tx.Query(`
+		UPDATE attachments SET message_id = $1
+		WHERE id = ANY($2::uuid[]) AND uploader_id = $3 AND message_id IS NULL
+		RETURNING `+attachmentColumns,
+		messageID, pq.Array(ids), uploaderID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	attachments := []model.Attachment{}
+
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, *a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(attachments) < len(ids) {
+		return nil, ErrAttachmentUnavailable
+	}
+
+	return attachments, nil
+}
+
+// ListByMessages returns the attachments of each message, keyed by message ID
+// and in upload order.
+func (r *AttachmentRepository) ListByMessages(messageIDs []string) (map[string][]model.Attachment, error) {
+	attachments := make(map[string][]model.Attachment)
+
+	if len(messageIDs) == 0 {
+		return attachments, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+attachmentColumns+`
+		FROM attachments
+		WHERE message_id = ANY($1::uuid[])
+		ORDER BY created_at, id
+	`, pq.Array(messageIDs))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		attachments[a.MessageID] = append(attachments[a.MessageID], *a)
+	}
+
+	return attachments, rows.Err()
+}
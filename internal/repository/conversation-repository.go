@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/mauFade/playzy/internal/model"
+)
+
+type ConversationRepositoryInterface interface {
+	Create(c *model.Conversation, memberIDs []string) error
+	FindByID(id string) (*model.Conversation, error)
+	FindDirect(userID, otherUserID string) (*model.Conversation, error)
+	FindOrCreateDirect(userID, otherUserID string) (*model.Conversation, error)
+	ListMemberIDs(conversationID string) ([]string, error)
+	FindBySessionID(sessionID string) (*model.Conversation, error)
+	AddMember(conversationID, userID string) error
+	RemoveMember(conversationID, userID string) error
+	Archive(conversationID string, at time.Time) error
+}
+
+// conversationColumns lists the columns read by scanConversation, in order.
+const conversationColumns = `id, kind, COALESCE(name, ''), created_at, COALESCE(session_id::text, ''), archived_at`
+
+func scanConversation(row rowScanner) (*model.Conversation, error) {
+	var c model.Conversation
+
+	if err := row.Scan(&c.ID, &c.Kind, &c.Name, &c.CreatedAt, &c.SessionID, &c.ArchivedAt); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+type ConversationRepository struct {
+	db *sql.DB
+}
+
+func NewConversationRepository(d *sql.DB) *ConversationRepository {
+	return &ConversationRepository{
+		db: d,
+	}
+}
+
+// directKey identifies the direct conversation of two users regardless of
+// who wrote first. It sorts like LEAST/GREATEST on the uuid columns.
+func directKey(userID, otherUserID string) (string, error) {
+	a, err := uuid.Parse(userID)
+
+	if err != nil {
+		return "", err
+	}
+
+	b, err := uuid.Parse(otherUserID)
+
+	if err != nil {
+		return "", err
+	}
+
+	if b.String() < a.String() {
+		a, b = b, a
+	}
+
+	return a.String() + ":" + b.String(), nil
+}
+
+// Create stores a conversation along with its members.
+func (r *ConversationRepository) Create(c *model.Conversation, memberIDs []string) error {
+	tx, err := r.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO conversations (kind, name, session_id) VALUES ($1, NULLIF($2, ''), NULLIF($3, '')::uuid)
+		RETURNING id, created_at
+	`, c.Kind, c.Name, c.SessionID).Scan(&c.ID, &c.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO conversation_members (conversation_id, user_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`, c.ID, pq.Array(memberIDs))
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ConversationRepository) FindByID(id string) (*model.Conversation, error) {
+	return r.findOne(`SELECT `+conversationColumns+` FROM conversations WHERE id = $1`, id)
+}
+
+// FindBySessionID returns the lobby chat of a session.
+func (r *ConversationRepository) FindBySessionID(sessionID string) (*model.Conversation, error) {
+	return r.findOne(`SELECT `+conversationColumns+` FROM conversations WHERE session_id = $1`, sessionID)
+}
+
+func (r *ConversationRepository) findOne(query string, args ...any) (*model.Conversation, error) {
+	c, err := scanConversation(r.db.QueryRow(query, args...))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// FindDirect returns the direct conversation of two users, or nil when they
+// never wrote to each other.
+func (r *ConversationRepository) FindDirect(userID, otherUserID string) (*model.Conversation, error) {
+	key, err := directKey(userID, otherUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.findOne(`SELECT `+conversationColumns+` FROM conversations WHERE direct_key = $1`, key)
+}
+
+// FindOrCreateDirect returns the direct conversation of two users, creating it
+// on their first message.
+func (r *ConversationRepository) FindOrCreateDirect(userID, otherUserID string) (*model.Conversation, error) {
+	key, err := directKey(userID, otherUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := r.findOne(`SELECT `+conversationColumns+` FROM conversations WHERE direct_key = $1`, key)
+
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	tx, err := r.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	// Another message may create it concurrently; the unique key keeps one
+	c, err := scanConversation(tx.QueryRow(`
+		INSERT INTO conversations (kind, direct_key) VALUES ($1, $2)
+		ON CONFLICT (direct_key) DO UPDATE SET direct_key = EXCLUDED.direct_key
+		RETURNING `+conversationColumns, model.ConversationDirect, key))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO conversation_members (conversation_id, user_id)
+		VALUES ($1, $2), ($1, $3)
+		ON CONFLICT DO NOTHING
+	`, c.ID, userID, otherUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *ConversationRepository) ListMemberIDs(conversationID string) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id FROM conversation_members WHERE conversation_id = $1 ORDER BY joined_at
+	`, conversationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	members := []string{}
+
+	for rows.Next() {
+		var userID string
+
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+
+		members = append(members, userID)
+	}
+
+	return members, rows.Err()
+}
+
+func (r *ConversationRepository) AddMember(conversationID, userID string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO conversation_members (conversation_id, user_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, conversationID, userID)
+
+	return err
+}
+
+func (r *ConversationRepository) RemoveMember(conversationID, userID string) error {
+	_, err := r.db.Exec(`
+		DELETE FROM conversation_members WHERE conversation_id = $1 AND user_id = $2
+	`, conversationID, userID)
+
+	return err
+}
+
+// Archive makes a conversation read-only. Archiving it again keeps the first
+// timestamp.
+func (r *ConversationRepository) Archive(conversationID string, at time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE conversations SET archived_at = $2 WHERE id = $1 AND archived_at IS NULL
+	`, conversationID, at)
+
+	return err
+}
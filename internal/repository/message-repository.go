@@ -2,14 +2,68 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
 
+	"github.com/lib/pq"
+	"github.com/mauFade/playzy/internal/dto"
 	"github.com/mauFade/playzy/internal/model"
 )
 
 type MessageRepositoryInterface interface {
-	Create(m model.Message) error
-	List(fstUserId, scdUserId string, limit, offset int) ([]model.Message, error)
+	Create(m *model.Message) error
+	FindByClientID(senderID, clientID string) (*model.Message, error)
+	FindByID(id string) (*model.Message, error)
+	List(fstUserId, scdUserId string, page dto.MessagePage) ([]model.Message, error)
 	SetMessagesIsRead(userId, otherUserID string) error
+	MarkReadUpTo(readerID, senderID string, upTo, readAt time.Time) (int64, error)
+	ListConversationPartners(userID string) ([]string, error)
+	ListInbox(userID string, limit, offset int) ([]dto.InboxEntry, int, error)
+	Edit(m *model.Message, previousContent string) error
+	SoftDelete(m *model.Message) error
+	FindByIDs(ids []string) ([]model.Message, error)
+	ListByConversation(conversationID string, page dto.MessagePage) ([]model.Message, error)
+	Search(userID, query string, before *dto.MessageCursor, limit int) ([]dto.MessageSearchHit, error)
+	ListMissed(userID string, since *dto.MessageCursor, limit int) ([]model.Message, error)
+	MarkDelivered(receiverID string, ids []string, at time.Time) error
+}
+
+// ErrMessageDeleted is returned when changing a message that was deleted.
+var ErrMessageDeleted = errors.New("message was deleted")
+
+// messageColumns lists the columns read by scanMessage, in order.
+const messageColumns = `id, content, user_id, COALESCE(receiver_id::text, ''), created_at, is_read, COALESCE(client_id, ''), read_at, edited_at, deleted_at, COALESCE(reply_to_id::text, ''), COALESCE(conversation_id::text, ''), delivered_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMessage(row rowScanner) (*model.Message, error) {
+	var msg model.Message
+
+	err := row.Scan(
+		&msg.ID,
+		&msg.Content,
+		&msg.SenderID,
+		&msg.ReceiverID,
+		&msg.Timestamp,
+		&msg.IsRead,
+		&msg.ClientID,
+		&msg.ReadAt,
+		&msg.EditedAt,
+		&msg.DeletedAt,
+		&msg.ReplyToID,
+		&msg.ConversationID,
+		&msg.DeliveredAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
 }
 
 type MessageRepository struct {
@@ -17,54 +71,172 @@ type MessageRepository struct {
 }
 
 func NewMessageRepository(d *sql.DB) *MessageRepository {
-	r := &MessageRepository{
+	return &MessageRepository{
 		db: d,
 	}
+}
 
-	r.db.Exec(`
-		CREATE TABLE IF NOT EXISTS messages (
-				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-				content TEXT NOT NULL,
-				user_id UUID NOT NULL REFERENCES users(id),
-				receiver_id UUID NOT NULL REFERENCES users(id),
-				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-				is_read BOOLEAN DEFAULT false
-		);
+// Create stores the message and fills in the ID and timestamp assigned by the
+// database. Shared files are linked to the message in the same transaction,
+// so it fails with ErrAttachmentUnavailable if any of them can't be.
+func (r *MessageRepository) Create(m *model.Message) error {
+	var receiverID, clientID, replyToID, conversationID sql.NullString
 
-		CREATE INDEX IF NOT EXISTS idx_messages_user_id ON messages(user_id);
-		CREATE INDEX IF NOT EXISTS idx_messages_receiver_id ON messages(receiver_id);
-		CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at);
-	`)
+	if m.ReceiverID != "" {
+		receiverID = sql.NullString{String: m.ReceiverID, Valid: true}
+	}
 
-	return r
-}
+	if m.ClientID != "" {
+		clientID = sql.NullString{String: m.ClientID, Valid: true}
+	}
 
-func (r *MessageRepository) Create(m model.Message) error {
-	var messageID string
+	if m.ReplyToID != "" {
+		replyToID = sql.NullString{String: m.ReplyToID, Valid: true}
+	}
 
-	err := r.db.QueryRow(`
-        INSERT INTO messages (content, user_id, receiver_id, created_at, is_read)
-        VALUES ($1, $2, $3, $4, $5)
-        RETURNING id
-    `, m.Content, m.SenderID, m.ReceiverID, m.Timestamp, m.IsRead).Scan(&messageID)
+	if m.ConversationID != "" {
+		conversationID = sql.NullString{String: m.ConversationID, Valid: true}
+	}
+
+	tx, err := r.db.Begin()
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+        INSERT INTO messages (content, user_id, receiver_id, created_at, is_read, client_id, reply_to_id, conversation_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `, m.Content, m.SenderID, receiverID, m.Timestamp, m.IsRead, clientID, replyToID, conversationID).Scan(&m.ID, &m.Timestamp)
+
+	if err != nil {
+		return err
+	}
+
+	if m.HasAttachments() {
+		linked, err := attachToMessage(tx, m.ID.String(), m.SenderID, m.AttachmentIDs())
+
+		if err != nil {
+			return err
+		}
+
+		m.Attachments = linked
+	}
+
+	return tx.Commit()
+}
+
+func (r *MessageRepository) FindByClientID(senderID, clientID string) (*model.Message, error) {
+	row := r.db.QueryRow(`
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE user_id = $1 AND client_id = $2
+	`, senderID, clientID)
+
+	msg, err := scanMessage(row)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func (r *MessageRepository) FindByID(id string) (*model.Message, error) {
+	row := r.db.QueryRow(`SELECT `+messageColumns+` FROM messages WHERE id = $1`, id)
+
+	msg, err := scanMessage(row)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// FindByIDs returns the messages with the given IDs, in no particular order.
+func (r *MessageRepository) FindByIDs(ids []string) ([]model.Message, error) {
+	ms := []model.Message{}
+
+	if len(ids) == 0 {
+		return ms, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+messageColumns+` FROM messages WHERE id = ANY($1::uuid[])`, pq.Array(ids))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ms = append(ms, *msg)
+	}
+
+	return ms, rows.Err()
+}
+
+// List returns a page of the one-to-one conversation between two users in
+// chronological order, using (created_at, id) keyset pagination.
+func (r *MessageRepository) List(fstUserId, scdUserId string, page dto.MessagePage) ([]model.Message, error) {
+	return r.listPage(`receiver_id IS NOT NULL
+			AND LEAST(user_id, receiver_id) = LEAST($1::uuid, $2::uuid)
+			AND GREATEST(user_id, receiver_id) = GREATEST($1::uuid, $2::uuid)`,
+		[]any{fstUserId, scdUserId}, page)
 }
 
-func (r *MessageRepository) List(fstUserId, scdUserId string, limit, offset int) ([]model.Message, error) {
-	query := `
-		SELECT id, content, user_id, receiver_id, created_at, is_read
+// ListByConversation returns a page of a conversation in chronological order,
+// using the same keyset pagination as List.
+func (r *MessageRepository) ListByConversation(conversationID string, page dto.MessagePage) ([]model.Message, error) {
+	return r.listPage(`conversation_id = $1`, []any{conversationID}, page)
+}
+
+// listPage runs a keyset-paginated query over the messages matching where,
+// whose placeholders are numbered from $1 to match args.
+func (r *MessageRepository) listPage(where string, args []any, page dto.MessagePage) ([]model.Message, error) {
+	n := len(args)
+	args = append(args, page.Limit)
+	keyset := ""
+	order := "DESC"
+
+	switch {
+	case page.Before != nil:
+		keyset = fmt.Sprintf("AND (created_at, id) < ($%d, $%d)", n+2, n+3)
+		args = append(args, page.Before.CreatedAt, page.Before.ID)
+	case page.After != nil:
+		keyset = fmt.Sprintf("AND (created_at, id) > ($%d, $%d)", n+2, n+3)
+		order = "ASC"
+		args = append(args, page.After.CreatedAt, page.After.ID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+messageColumns+`
 		FROM messages
-		WHERE (user_id = $1 AND receiver_id = $2) OR (user_id = $2 AND receiver_id = $1)
-		ORDER BY created_at DESC
-		LIMIT $3 OFFSET $4
-	`
+		WHERE %s
+			%s
+		ORDER BY created_at %s, id %s
+		LIMIT $%d
+	`, where, keyset, order, order, n+1)
 
-	rows, err := r.db.Query(query, fstUserId, scdUserId, limit, offset)
+	rows, err := r.db.Query(query, args...)
 
 	if err != nil {
 		return nil, err
@@ -75,20 +247,17 @@ func (r *MessageRepository) List(fstUserId, scdUserId string, limit, offset int)
 	messages := []model.Message{}
 
 	for rows.Next() {
-		var msg model.Message
-
-		err := rows.Scan(&msg.ID,
-			&msg.Content,
-			&msg.SenderID,
-			&msg.ReceiverID,
-			&msg.Timestamp,
-			&msg.IsRead)
+		msg, err := scanMessage(rows)
 
 		if err != nil {
 			return nil, err
 		}
 
-		messages = append(messages, msg)
+		messages = append(messages, *msg)
+	}
+
+	if order == "DESC" {
+		slices.Reverse(messages)
 	}
 
 	return messages, nil
@@ -97,7 +266,7 @@ func (r *MessageRepository) List(fstUserId, scdUserId string, limit, offset int)
 func (r *MessageRepository) SetMessagesIsRead(userId, otherUserID string) error {
 	_, err := r.db.Exec(`
 			UPDATE messages
-			SET is_read = true
+			SET is_read = true, read_at = NOW()
 			WHERE receiver_id = $1 AND user_id = $2 AND is_read = false
 		`, userId, otherUserID)
 
@@ -107,3 +276,309 @@ func (r *MessageRepository) SetMessagesIsRead(userId, otherUserID string) error
 
 	return nil
 }
+
+// MarkReadUpTo marks as read every unread message senderID sent to readerID up
+// to and including upTo, and returns how many messages changed.
+func (r *MessageRepository) MarkReadUpTo(readerID, senderID string, upTo, readAt time.Time) (int64, error) {
+	res, err := r.db.Exec(`
+			UPDATE messages
+			SET is_read = true, read_at = $4
+			WHERE receiver_id = $1 AND user_id = $2 AND is_read = false AND created_at <= $3
+		`, readerID, senderID, upTo, readAt)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// ListConversationPartners returns the users who share a conversation with
+// userID, direct or group.
+func (r *MessageRepository) ListConversationPartners(userID string) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT other.user_id
+		FROM conversation_members me
+		JOIN conversation_members other ON other.conversation_id = me.conversation_id
+		WHERE me.user_id = $1 AND other.user_id <> $1
+	`, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	partners := []string{}
+
+	for rows.Next() {
+		var partnerID string
+
+		if err := rows.Scan(&partnerID); err != nil {
+			return nil, err
+		}
+
+		partners = append(partners, partnerID)
+	}
+
+	return partners, nil
+}
+
+// inboxPreviewLength is how many characters of the last message are returned
+// in the inbox.
+const inboxPreviewLength = 100
+
+// ListInbox returns one entry per conversation of userID that has messages,
+// direct or group, most recent first, along with the total number of those
+// conversations. Reads are only tracked in direct conversations, so group
+// entries have no unread count.
+func (r *MessageRepository) ListInbox(userID string, limit, offset int) ([]dto.InboxEntry, int, error) {
+	rows, err := r.db.Query(`
+		SELECT c.id, c.kind, COALESCE(c.name, ''), partner.id, COALESCE(partner.gamertag, ''),
+			lm.id, LEFT(lm.content, $4), lm.user_id, lm.created_at,
+			(SELECT COUNT(*) FROM messages unread
+				WHERE unread.conversation_id = c.id AND unread.receiver_id = $1 AND unread.is_read = false),
+			COUNT(*) OVER ()
+		FROM conversation_members me
+		JOIN conversations c ON c.id = me.conversation_id
+		JOIN LATERAL (
+			SELECT id, content, user_id, created_at
+			FROM messages
+			WHERE messages.conversation_id = c.id
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		) lm ON true
+		LEFT JOIN LATERAL (
+			SELECT users.id, users.gamertag
+			FROM conversation_members other
+			JOIN users ON users.id = other.user_id
+			WHERE c.kind = $5 AND other.conversation_id = c.id
+			ORDER BY other.user_id = $1
+			LIMIT 1
+		) partner ON true
+		WHERE me.user_id = $1
+		ORDER BY lm.created_at DESC, lm.id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset, inboxPreviewLength, model.ConversationDirect)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	entries := []dto.InboxEntry{}
+	total := 0
+
+	for rows.Next() {
+		var e dto.InboxEntry
+
+		err := rows.Scan(
+			&e.ConversationID,
+			&e.Kind,
+			&e.Name,
+			&e.PartnerID,
+			&e.PartnerGamertag,
+			&e.LastMessageID,
+			&e.LastMessagePreview,
+			&e.LastMessageSenderID,
+			&e.LastMessageAt,
+			&e.UnreadCount,
+			&total,
+		)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, total, nil
+}
+
+// Edit saves the new content of an edited message and keeps the previous one
+// in message_edits. It returns ErrMessageDeleted when the message was deleted
+// in the meantime.
+func (r *MessageRepository) Edit(m *model.Message, previousContent string) error {
+	tx, err := r.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE messages SET content = $2, edited_at = $3 WHERE id = $1 AND deleted_at IS NULL
+	`, m.ID, m.Content, m.EditedAt)
+
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	// The message was deleted since it was loaded, so there is nothing to keep
+	// a history of
+	if count == 0 {
+		return ErrMessageDeleted
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO message_edits (message_id, previous_content, edited_at)
+		VALUES ($1, $2, $3)
+	`, m.ID, previousContent, m.EditedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SoftDelete tombstones a message: the row stays so the conversation keeps its
+// shape, but the content is cleared along with its edit history.
+func (r *MessageRepository) SoftDelete(m *model.Message) error {
+	tx, err := r.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE messages SET content = '', deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL
+	`, m.ID, m.DeletedAt)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM message_edits WHERE message_id = $1`, m.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Search returns the messages matching the query in conversations the user
+// takes part in, newest first and older than before when it is set. The
+// 'simple' configuration is used so codes and nicknames match as typed,
+// whatever the language of the chat.
+func (r *MessageRepository) Search(userID, query string, before *dto.MessageCursor, limit int) ([]dto.MessageSearchHit, error) {
+	args := []any{userID, query, limit}
+	keyset := ""
+
+	if before != nil {
+		keyset = "AND (m.created_at, m.id) < ($4, $5)"
+		args = append(args, before.CreatedAt, before.ID)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT m.id, m.user_id, COALESCE(m.receiver_id::text, ''), COALESCE(m.conversation_id::text, ''),
+			ts_headline('simple',
+				replace(replace(replace(m.content, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+				q.query, 'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2'),
+			m.created_at
+		FROM messages m, websearch_to_tsquery('simple', $2) AS q(query)
+		WHERE m.search_vector @@ q.query
+			AND m.deleted_at IS NULL
+			AND (
+				m.user_id = $1
+				OR m.receiver_id = $1
+				OR m.conversation_id IN (SELECT conversation_id FROM conversation_members WHERE user_id = $1)
+			)
+			`+keyset+`
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $3
+	`, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	hits := []dto.MessageSearchHit{}
+
+	for rows.Next() {
+		var hit dto.MessageSearchHit
+
+		if err := rows.Scan(&hit.ID, &hit.SenderID, &hit.ReceiverID, &hit.ConversationID, &hit.Snippet, &hit.Timestamp); err != nil {
+			return nil, err
+		}
+
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
+
+// ListMissed returns, in chronological order, what a reconnecting user has to
+// catch up on: one-to-one messages never delivered to them and, when since is
+// set, every message of their conversations after it. Group messages are only
+// tracked through since, as delivered_at can't hold one state per member.
+func (r *MessageRepository) ListMissed(userID string, since *dto.MessageCursor, limit int) ([]model.Message, error) {
+	args := []any{userID, limit}
+	missed := "receiver_id = $1 AND delivered_at IS NULL"
+
+	if since != nil {
+		missed = `(` + missed + `)
+			OR ((created_at, id) > ($3, $4) AND (
+				user_id = $1
+				OR receiver_id = $1
+				OR conversation_id IN (SELECT conversation_id FROM conversation_members WHERE user_id = $1)
+			))`
+		args = append(args, since.CreatedAt, since.ID)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE deleted_at IS NULL AND (`+missed+`)
+		ORDER BY created_at, id
+		LIMIT $2
+	`, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	messages := []model.Message{}
+
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, *msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// MarkDelivered records when the given messages reached their receiver. Messages
+// already delivered keep their first delivery time, so every instance the
+// receiver is connected to may call it for the same message.
+func (r *MessageRepository) MarkDelivered(receiverID string, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE messages SET delivered_at = $3
+		WHERE id = ANY($2::uuid[]) AND receiver_id = $1 AND delivered_at IS NULL
+	`, receiverID, pq.Array(ids), at)
+
+	return err
+}
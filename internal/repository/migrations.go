@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrationLock is the advisory lock key held while migrating, so instances
+// starting together don't apply the same migration twice.
+const migrationLock = 7_201_901
+
+// migration is one step of the schema. Steps are applied in order, once, and
+// never edited after they ship: later changes go in new steps.
+type migration struct {
+	name string
+	up   func(tx *sql.Tx) error
+}
+
+// execMigration is a migration made of plain SQL statements.
+func execMigration(statements string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(statements)
+		return err
+	}
+}
+
+// migrations is the schema history. Steps use IF NOT EXISTS so databases
+// created before migrations existed are picked up as they are.
+var migrations = []migration{
+	{name: "create users", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS users (id UUID PRIMARY KEY, name VARCHAR NOT NULL, email VARCHAR NOT NULL, phone VARCHAR NOT NULL, password VARCHAR NOT NULL, gamertag VARCHAR NOT NULL, is_deleted BOOLEAN NOT NULL, deleted_at TIMESTAMP NULL, updated_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL);
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE NULL;
+	`)},
+	{name: "create messages", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS messages (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				content TEXT NOT NULL,
+				user_id UUID NOT NULL REFERENCES users(id),
+				receiver_id UUID NOT NULL REFERENCES users(id),
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				is_read BOOLEAN DEFAULT false
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_messages_user_id ON messages(user_id);
+		CREATE INDEX IF NOT EXISTS idx_messages_receiver_id ON messages(receiver_id);
+		CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at);
+
+		ALTER TABLE messages ADD COLUMN IF NOT EXISTS client_id VARCHAR NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_user_client_id ON messages(user_id, client_id) WHERE client_id IS NOT NULL;
+
+		ALTER TABLE messages ADD COLUMN IF NOT EXISTS read_at TIMESTAMP WITH TIME ZONE NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_messages_user_id_created_at ON messages(user_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_messages_receiver_id_created_at ON messages(receiver_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_messages_unread ON messages(receiver_id, user_id) WHERE is_read = false;
+		CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(LEAST(user_id, receiver_id), GREATEST(user_id, receiver_id), created_at, id);
+
+		ALTER TABLE messages ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE NULL;
+		ALTER TABLE messages ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE NULL;
+
+		CREATE TABLE IF NOT EXISTS message_edits (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+				previous_content TEXT NOT NULL,
+				edited_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_message_edits_message_id ON message_edits(message_id, edited_at);
+
+		ALTER TABLE messages ADD COLUMN IF NOT EXISTS reply_to_id UUID NULL REFERENCES messages(id);
+
+		ALTER TABLE messages ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP WITH TIME ZONE NULL;
+		CREATE INDEX IF NOT EXISTS idx_messages_undelivered ON messages(receiver_id, created_at, id) WHERE delivered_at IS NULL;
+	`)},
+	{name: "add message search", up: execMigration(`
+		ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED;
+		CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector);
+	`)},
+	{name: "create message reactions", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS message_reactions (
+				message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+				user_id UUID NOT NULL REFERENCES users(id),
+				emoji VARCHAR(32) NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (message_id, user_id, emoji)
+		);
+	`)},
+	{name: "create attachments", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS attachments (
+				id UUID PRIMARY KEY,
+				uploader_id UUID NOT NULL REFERENCES users(id),
+				message_id UUID NULL REFERENCES messages(id) ON DELETE CASCADE,
+				file_name VARCHAR NOT NULL,
+				content_type VARCHAR NOT NULL,
+				size BIGINT NOT NULL,
+				width INTEGER NOT NULL DEFAULT 0,
+				height INTEGER NOT NULL DEFAULT 0,
+				storage_key VARCHAR NOT NULL,
+				thumbnail_key VARCHAR NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_attachments_message_id ON attachments(message_id);
+	`)},
+	{name: "create conversations", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS conversations (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				kind VARCHAR NOT NULL,
+				name VARCHAR NULL,
+				direct_key VARCHAR NULL UNIQUE,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS conversation_members (
+				conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+				user_id UUID NOT NULL REFERENCES users(id),
+				joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (conversation_id, user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_conversation_members_user_id ON conversation_members(user_id);
+
+		ALTER TABLE messages ADD COLUMN IF NOT EXISTS conversation_id UUID NULL REFERENCES conversations(id);
+		ALTER TABLE messages ALTER COLUMN receiver_id DROP NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_messages_conversation_id ON messages(conversation_id, created_at, id);
+
+		ALTER TABLE conversations ADD COLUMN IF NOT EXISTS session_id UUID NULL;
+		ALTER TABLE conversations ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_conversations_session_id ON conversations(session_id) WHERE session_id IS NOT NULL;
+	`)},
+	// One-to-one messages sent before conversations existed are moved into
+	// two-member direct conversations
+	{name: "backfill direct conversations", up: execMigration(`
+		INSERT INTO conversations (kind, direct_key, created_at)
+		SELECT 'direct', LEAST(user_id, receiver_id)::text || ':' || GREATEST(user_id, receiver_id)::text, MIN(created_at)
+		FROM messages
+		WHERE conversation_id IS NULL AND receiver_id IS NOT NULL
+		GROUP BY LEAST(user_id, receiver_id), GREATEST(user_id, receiver_id)
+		ON CONFLICT (direct_key) DO NOTHING;
+
+		INSERT INTO conversation_members (conversation_id, user_id, joined_at)
+		SELECT c.id, members.user_id, c.created_at
+		FROM conversations c
+		CROSS JOIN LATERAL (VALUES
+			(split_part(c.direct_key, ':', 1)::uuid),
+			(split_part(c.direct_key, ':', 2)::uuid)
+		) AS members(user_id)
+		WHERE c.kind = 'direct'
+		ON CONFLICT DO NOTHING;
+
+		UPDATE messages m
+		SET conversation_id = c.id
+		FROM conversations c
+		WHERE m.conversation_id IS NULL AND m.receiver_id IS NOT NULL
+			AND c.direct_key = LEAST(m.user_id, m.receiver_id)::text || ':' || GREATEST(m.user_id, m.receiver_id)::text;
+	`)},
+	{name: "create sessions", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS sessions (id UUID PRIMARY KEY, game VARCHAR NOT NULL, user_id UUID NOT NULL, objective VARCHAR NOT NULL, rank VARCHAR NULL, is_ranked BOOLEAN NOT NULL, updated_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL, CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE);
+
+		ALTER TABLE sessions ADD COLUMN IF NOT EXISTS max_players INTEGER NOT NULL DEFAULT 5;
+		ALTER TABLE sessions ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'open';
+		CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status, created_at);
+		ALTER TABLE sessions ADD COLUMN IF NOT EXISTS starts_at TIMESTAMP WITH TIME ZONE NULL;
+		ALTER TABLE sessions ADD COLUMN IF NOT EXISTS time_zone VARCHAR NOT NULL DEFAULT 'UTC';
+
+		CREATE TABLE IF NOT EXISTS session_members (
+				session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				status VARCHAR(16) NOT NULL,
+				requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				responded_at TIMESTAMP WITH TIME ZONE NULL,
+				PRIMARY KEY (session_id, user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_session_members_user_id ON session_members(user_id);
+	`)},
+	// A lobby outlives its session as a plain group chat, so deleting the
+	// session only unlinks it
+	{name: "link lobbies to sessions", up: execMigration(`
+		UPDATE conversations SET session_id = NULL
+		WHERE session_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM sessions WHERE sessions.id = conversations.session_id);
+
+		ALTER TABLE conversations ADD CONSTRAINT fk_conversations_session FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL;
+	`)},
+	// Holds websocket deliveries too large for NOTIFY while the other
+	// instances read them
+	{name: "create websocket outbox", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS websocket_outbox (
+				id UUID PRIMARY KEY,
+				payload TEXT NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_websocket_outbox_created_at ON websocket_outbox(created_at);
+	`)},
+}
+
+// Migrate brings the schema up to date. It runs once at startup, before the
+// server takes requests, and fails if any step does.
+func Migrate(db *sql.DB) error {
+	err := withMigrationLock(db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+					version INTEGER PRIMARY KEY,
+					name VARCHAR NOT NULL,
+					applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+			)
+		`)
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	for i, m := range migrations {
+		version := i + 1
+
+		err := withMigrationLock(db, func(tx *sql.Tx) error {
+			var applied bool
+
+			err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied)
+
+			if err != nil || applied {
+				return err
+			}
+
+			if err := m.up(tx); err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, version, m.name)
+			return err
+		})
+
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// withMigrationLock runs fn in a transaction holding the migration lock.
+func withMigrationLock(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLock); err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
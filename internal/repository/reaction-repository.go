@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+)
+
+type ReactionRepositoryInterface interface {
+	Add(reaction *model.Reaction) (bool, error)
+	Remove(messageID, userID, emoji string) (bool, error)
+	CountByMessages(messageIDs []string) (map[string][]dto.ReactionCount, error)
+}
+
+type ReactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(d *sql.DB) *ReactionRepository {
+	return &ReactionRepository{
+		db: d,
+	}
+}
+
+// Add stores a reaction. It returns false when the user had already reacted to
+// the message with that emoji.
+func (r *ReactionRepository) Add(reaction *model.Reaction) (bool, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO message_reactions (message_id, user_id, emoji, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, reaction.MessageID, reaction.UserID, reaction.Emoji, reaction.CreatedAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+
+	return count > 0, err
+}
+
+// Remove deletes a reaction. It returns false when there was nothing to remove.
+func (r *ReactionRepository) Remove(messageID, userID, emoji string) (bool, error) {
+	res, err := r.db.Exec(`
+		DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3
+	`, messageID, userID, emoji)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+
+	return count > 0, err
+}
+
+// CountByMessages aggregates the reactions of each message, keyed by message
+// ID. Emojis are ordered by when they were first used on the message.
+func (r *ReactionRepository) CountByMessages(messageIDs []string) (map[string][]dto.ReactionCount, error) {
+	counts := make(map[string][]dto.ReactionCount)
+
+	if len(messageIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT message_id, emoji, COUNT(*), ARRAY_AGG(user_id::text ORDER BY created_at)
+		FROM message_reactions
+		WHERE message_id = ANY($1::uuid[])
+		GROUP BY message_id, emoji
+		ORDER BY message_id, MIN(created_at)
+	`, pq.Array(messageIDs))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID string
+		var count dto.ReactionCount
+
+		if err := rows.Scan(&messageID, &count.Emoji, &count.Count, pq.Array(&count.UserIDs)); err != nil {
+			return nil, err
+		}
+
+		counts[messageID] = append(counts[messageID], count)
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mauFade/playzy/internal/model"
+)
+
+type SessionMemberRepositoryInterface interface {
+	Request(m *model.SessionMember) (bool, error)
+	FindOne(sessionID, userID string) (*model.SessionMember, error)
+	Accept(sessionID, userID string, at time.Time) (bool, error)
+	SetStatus(sessionID, userID string, from []model.SessionMemberStatus, to model.SessionMemberStatus, at time.Time) (bool, error)
+	Remove(sessionID, userID string) (bool, error)
+	ListBySessions(sessionIDs []string) (map[string][]model.SessionMember, error)
+}
+
+// ErrNoOpenSlots is returned when accepting a player into a session that is
+// already full.
+var ErrNoOpenSlots = errors.New("session has no open slots")
+
+// sessionMemberColumns lists the columns read by scanSessionMember, in order.
+const sessionMemberColumns = `session_members.session_id, session_members.user_id, COALESCE(users.gamertag, ''), session_members.status, session_members.requested_at, session_members.responded_at`
+
+func scanSessionMember(row rowScanner) (*model.SessionMember, error) {
+	var m model.SessionMember
+
+	if err := row.Scan(&m.SessionID, &m.UserID, &m.Gamertag, &m.Status, &m.RequestedAt, &m.RespondedAt); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+type SessionMemberRepository struct {
+	db *sql.DB
+}
+
+func NewSessionMemberRepository(d *sql.DB) *SessionMemberRepository {
+	return &SessionMemberRepository{
+		db: d,
+	}
+}
+
+// Request stores a join request. It returns false when the user already has a
+// row in the session, whatever its status.
+func (r *SessionMemberRepository) Request(m *model.SessionMember) (bool, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO session_members (session_id, user_id, status, requested_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, m.SessionID, m.UserID, m.Status, m.RequestedAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+
+	return count > 0, err
+}
+
+func (r *SessionMemberRepository) FindOne(sessionID, userID string) (*model.SessionMember, error) {
+	m, err := scanSessionMember(r.db.QueryRow(`
+		SELECT `+sessionMemberColumns+`
+		FROM session_members
+		LEFT JOIN users ON users.id = session_members.user_id
+		WHERE session_members.session_id = $1 AND session_members.user_id = $2
+	`, sessionID, userID))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Accept lets a pending player in if the session still has room, and marks
+// the session full when they take the last slot. It returns false when the
+// request is no longer pending.
+func (r *SessionMemberRepository) Accept(sessionID, userID string, at time.Time) (bool, error) {
+	return r.change(sessionID, func(tx *sql.Tx) (bool, error) {
+		var maxPlayers, players int
+		var status string
+
+		err := tx.QueryRow(`
+			SELECT max_players, status, `+acceptedPlayers+` FROM sessions WHERE id = $1
+		`, sessionID).Scan(&maxPlayers, &status, &players)
+
+		if err != nil {
+			return false, err
+		}
+
+		if status != string(model.SessionOpen) || 1+players >= maxPlayers {
+			return false, ErrNoOpenSlots
+		}
+
+		return updateMemberStatus(tx, sessionID, userID, []model.SessionMemberStatus{model.MemberPending}, model.MemberAccepted, at)
+	})
+}
+
+// SetStatus moves a member to a new status only if they are still in one of
+// the from statuses, so two owners' devices answering the same request can't
+// both win. It returns false when nothing changed.
+func (r *SessionMemberRepository) SetStatus(sessionID, userID string, from []model.SessionMemberStatus, to model.SessionMemberStatus, at time.Time) (bool, error) {
+	return r.change(sessionID, func(tx *sql.Tx) (bool, error) {
+		return updateMemberStatus(tx, sessionID, userID, from, to, at)
+	})
+}
+
+// Remove deletes a member's row, letting them ask to join again later.
+func (r *SessionMemberRepository) Remove(sessionID, userID string) (bool, error) {
+	return r.change(sessionID, func(tx *sql.Tx) (bool, error) {
+		res, err := tx.Exec(`
+			DELETE FROM session_members WHERE session_id = $1 AND user_id = $2
+		`, sessionID, userID)
+
+		if err != nil {
+			return false, err
+		}
+
+		count, err := res.RowsAffected()
+
+		return count > 0, err
+	})
+}
+
+// change runs a roster change while holding the session row, so concurrent
+// accepts can't overfill it, then flips the session between open and full to
+// match the new roster.
+func (r *SessionMemberRepository) change(sessionID string, apply func(tx *sql.Tx) (bool, error)) (bool, error) {
+	tx, err := r.db.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT 1 FROM sessions WHERE id = $1 FOR UPDATE`, sessionID); err != nil {
+		return false, err
+	}
+
+	changed, err := apply(tx)
+
+	if err != nil || !changed {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE sessions
+		SET status = CASE WHEN 1 + `+acceptedPlayers+` >= max_players THEN $2 ELSE $3 END
+		WHERE id = $1 AND status = ANY($4)
+	`, sessionID, model.SessionFull, model.SessionOpen, pq.Array(sessionStatuses([]model.SessionStatus{model.SessionOpen, model.SessionFull})))
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func updateMemberStatus(tx *sql.Tx, sessionID, userID string, from []model.SessionMemberStatus, to model.SessionMemberStatus, at time.Time) (bool, error) {
+	statuses := make([]string, len(from))
+	for i, s := range from {
+		statuses[i] = string(s)
+	}
+
+	res, err := tx.Exec(`
+		UPDATE session_members SET status = $3, responded_at = $4
+		WHERE session_id = $1 AND user_id = $2 AND status = ANY($5)
+	`, sessionID, userID, to, at, pq.Array(statuses))
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+
+	return count > 0, err
+}
+
+// ListBySessions returns the pending and accepted members of each session,
+// keyed by session ID, in the order they asked to join.
+func (r *SessionMemberRepository) ListBySessions(sessionIDs []string) (map[string][]model.SessionMember, error) {
+	members := make(map[string][]model.SessionMember)
+
+	if len(sessionIDs) == 0 {
+		return members, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+sessionMemberColumns+`
+		FROM session_members
+		LEFT JOIN users ON users.id = session_members.user_id
+		WHERE session_members.session_id = ANY($1::uuid[]) AND session_members.status = ANY($2)
+		ORDER BY session_members.requested_at
+	`, pq.Array(sessionIDs), pq.Array([]string{string(model.MemberPending), string(model.MemberAccepted)}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		m, err := scanSessionMember(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		key := m.SessionID.String()
+		members[key] = append(members[key], *m)
+	}
+
+	return members, rows.Err()
+}
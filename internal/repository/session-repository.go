@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/mauFade/playzy/internal/dto"
 	"github.com/mauFade/playzy/internal/model"
 )
@@ -15,8 +17,35 @@ import (
 type SessionRepositoryInterface interface {
 	Create(s *model.SessionModel) error
 	FindByID(id uuid.UUID) (*model.SessionModel, error)
-	FindAvailable(page int, rank, game string) (*dto.SessionsPageResponse, error)
+	FindAvailable(filter dto.SessionFilter) (*dto.SessionsPageResponse, error)
 	Delete(id string) error
+	Update(s *model.SessionModel) (*model.SessionModel, error)
+	UpdateStatus(id string, from []model.SessionStatus, to model.SessionStatus, at time.Time) (bool, error)
+	ExpireStale(startedBefore, at time.Time) ([]string, error)
+}
+
+// ErrRosterTooLarge is returned when shrinking a session below the players it
+// already accepted.
+var ErrRosterTooLarge = errors.New("accepted players don't fit the session")
+
+// acceptedPlayers counts the accepted members of the session in the current
+// row.
+const acceptedPlayers = `(SELECT COUNT(*) FROM session_members WHERE session_members.session_id = sessions.id AND session_members.status = 'accepted')`
+
+// sessionColumns lists the columns read by scanSession, in order.
+const sessionColumns = `sessions.id, sessions.game, sessions.user_id, sessions.objective, sessions.rank, sessions.is_ranked, sessions.updated_at, sessions.created_at, sessions.max_players, sessions.status, sessions.starts_at, sessions.time_zone, ` + acceptedPlayers
+
+// sessionStart is when the session in the current row starts.
+const sessionStart = `COALESCE(sessions.starts_at, sessions.created_at)`
+
+func scanSession(row rowScanner) (*model.SessionModel, error) {
+	var s model.SessionModel
+
+	if err := row.Scan(&s.ID, &s.Game, &s.UserID, &s.Objective, &s.Rank, &s.IsRanked, &s.UpdatedAt, &s.CreatedAt, &s.MaxPlayers, &s.Status, &s.StartsAt, &s.TimeZone, &s.Players); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
 }
 
 type SessionRepository struct {
@@ -24,19 +53,15 @@ type SessionRepository struct {
 }
 
 func NewSessionRepository(d *sql.DB) *SessionRepository {
-	r := &SessionRepository{
+	return &SessionRepository{
 		db: d,
 	}
-
-	r.db.Exec("CREATE TABLE IF NOT EXISTS sessions (id UUID PRIMARY KEY, game VARCHAR NOT NULL, user_id UUID NOT NULL, objective VARCHAR NOT NULL, rank VARCHAR NULL, is_ranked BOOLEAN NOT NULL, updated_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL, CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE)")
-
-	return r
 }
 
 func (r *SessionRepository) Create(s *model.SessionModel) error {
 	query := `INSERT INTO sessions
-	(id, game, user_id, objective, rank, is_ranked, updated_at, created_at)
-	VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	(id, game, user_id, objective, rank, is_ranked, max_players, status, starts_at, time_zone, updated_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`
 
 	_, err := r.db.Exec(query,
@@ -46,6 +71,10 @@ func (r *SessionRepository) Create(s *model.SessionModel) error {
 		s.GetObjective(),
 		s.GetRank(),
 		s.GetIsRanked(),
+		s.GetMaxPlayers(),
+		s.GetStatus(),
+		s.GetStartsAt(),
+		s.GetTimeZone(),
 	)
 
 	if err != nil {
@@ -56,22 +85,9 @@ func (r *SessionRepository) Create(s *model.SessionModel) error {
 }
 
 func (r *SessionRepository) FindByID(id uuid.UUID) (*model.SessionModel, error) {
-	query := `SELECT * FROM sessions WHERE id = $1`
-
-	res := r.db.QueryRow(query, id.String())
-
-	var session model.SessionModel
-
-	if err := res.Scan(
-		&session.ID,
-		&session.Game,
-		&session.UserID,
-		&session.Objective,
-		&session.Rank,
-		&session.IsRanked,
-		&session.UpdatedAt,
-		&session.CreatedAt,
-	); err != nil {
+	session, err := scanSession(r.db.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, id.String()))
+
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -79,48 +95,70 @@ func (r *SessionRepository) FindByID(id uuid.UUID) (*model.SessionModel, error)
 		return nil, err
 	}
 
-	return &session, nil
+	return session, nil
 }
 
-func (r *SessionRepository) FindAvailable(page int, rank, game string) (*dto.SessionsPageResponse, error) {
+// FindAvailable lists a page of open sessions, newest first.
+func (r *SessionRepository) FindAvailable(filter dto.SessionFilter) (*dto.SessionsPageResponse, error) {
 	pageQtd := 6
 
-	rankQuery := ""
-	gameQuery := ""
+	page := max(filter.Page, 1)
 
-	if rank != "" {
-		rankQuery = "AND LOWER(sessions.rank) = '" + strings.ToLower(rank) + "'"
+	statuses := []model.SessionStatus{model.SessionOpen}
+	if filter.IncludeFull {
+		statuses = append(statuses, model.SessionFull)
 	}
 
-	if game != "" {
-		gameQuery = "AND LOWER(sessions.game) LIKE '%" + strings.ToLower(game) + "%'"
+	args := []any{pq.Array(sessionStatuses(statuses))}
+	conditions := []string{"users.is_deleted = 'false'", "sessions.status = ANY($1)"}
+
+	if filter.Rank != "" {
+		args = append(args, strings.ToLower(filter.Rank))
+		conditions = append(conditions, fmt.Sprintf("LOWER(sessions.rank) = $%d", len(args)))
 	}
 
-	query := fmt.Sprintf("SELECT sessions.*, users.id AS user_id, users.name, users.email, users.gamertag FROM sessions LEFT JOIN users ON sessions.user_id = users.id WHERE users.is_deleted = 'false' %s %s LIMIT %v OFFSET ($1 - 1) * %v",
-		rankQuery, gameQuery, pageQtd, pageQtd)
+	if filter.Game != "" {
+		args = append(args, strings.ToLower(filter.Game))
+		conditions = append(conditions, fmt.Sprintf("LOWER(sessions.game) LIKE '%%' || $%d || '%%'", len(args)))
+	}
 
-	rows, err := r.db.Query(query, page)
+	if filter.StartsFrom != nil {
+		args = append(args, *filter.StartsFrom)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", sessionStart, len(args)))
+	}
 
-	if err != nil {
-		return nil, err
+	if filter.StartsUntil != nil {
+		args = append(args, *filter.StartsUntil)
+		conditions = append(conditions, fmt.Sprintf("%s <= $%d", sessionStart, len(args)))
 	}
 
+	from := "FROM sessions JOIN users ON sessions.user_id = users.id WHERE " + strings.Join(conditions, " AND ")
+
 	var count int
 
-	err = r.db.QueryRow("SELECT COUNT(*) FROM sessions").Scan(&count)
+	if err := r.db.QueryRow("SELECT COUNT(*) "+from, args...).Scan(&count); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s, users.name, users.email, users.gamertag %s ORDER BY sessions.created_at DESC, sessions.id LIMIT %d OFFSET %d",
+		sessionColumns, from, pageQtd, (page-1)*pageQtd)
+
+	rows, err := r.db.Query(query, args...)
 
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	totalPages := int(math.Ceil(float64(count) / float64(pageQtd)))
 
-	var sessions []dto.SessionWithUser
+	sessions := []dto.SessionWithUser{}
 
 	for rows.Next() {
 		var s dto.SessionWithUser
 
-		err := rows.Scan(&s.ID, &s.Game, &s.UserID, &s.Objective, &s.Rank, &s.IsRanked, &s.UpdatedAt, &s.CreatedAt, &s.UserID, &s.UserName, &s.Email, &s.UserGamertag)
+		err := rows.Scan(&s.ID, &s.Game, &s.UserID, &s.Objective, &s.Rank, &s.IsRanked, &s.UpdatedAt, &s.CreatedAt, &s.MaxPlayers, &s.Status, &s.StartsAt, &s.TimeZone, &s.Players, &s.UserName, &s.Email, &s.UserGamertag)
 
 		if err != nil {
 			return nil, err
@@ -129,8 +167,8 @@ func (r *SessionRepository) FindAvailable(page int, rank, game string) (*dto.Ses
 		sessions = append(sessions, s)
 	}
 
-	if len(sessions) == 0 {
-		sessions = []dto.SessionWithUser{}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return &dto.SessionsPageResponse{
@@ -145,3 +183,123 @@ func (r *SessionRepository) Delete(id string) error {
 
 	return err
 }
+
+// Update saves the owner's edits and moves the session between open and full
+// to match its new size. It returns the stored session, or nil if it's gone,
+// and ErrRosterTooLarge when the accepted players don't fit the new size.
+func (r *SessionRepository) Update(s *model.SessionModel) (*model.SessionModel, error) {
+	tx, err := r.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	// Hold the row so a concurrent accept sees the new size
+	if _, err := tx.Exec(`SELECT 1 FROM sessions WHERE id = $1 FOR UPDATE`, s.GetID()); err != nil {
+		return nil, err
+	}
+
+	var players int
+
+	if err := tx.QueryRow(`SELECT `+acceptedPlayers+` FROM sessions WHERE id = $1`, s.GetID()).Scan(&players); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	// Shrinking the party never kicks anyone
+	if 1+players > s.GetMaxPlayers() {
+		return nil, ErrRosterTooLarge
+	}
+
+	_, err = tx.Exec(`
+		UPDATE sessions
+		SET game = $2, objective = $3, rank = $4, is_ranked = $5, max_players = $6, updated_at = $7,
+			starts_at = $11, time_zone = $12,
+			status = CASE
+				WHEN status <> ALL($8) THEN status
+				WHEN 1 + `+acceptedPlayers+` >= $6 THEN $9
+				ELSE $10
+			END
+		WHERE id = $1
+	`, s.GetID(), s.GetGame(), s.GetObjective(), s.GetRank(), s.GetIsRanked(), s.GetMaxPlayers(), s.GetUpdatedAt(),
+		pq.Array(sessionStatuses([]model.SessionStatus{model.SessionOpen, model.SessionFull})), model.SessionFull, model.SessionOpen,
+		s.GetStartsAt(), s.GetTimeZone())
+
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := scanSession(tx.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, s.GetID()))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return updated, tx.Commit()
+}
+
+// UpdateStatus moves a session to a new status only if it is still in one of
+// the from statuses. It returns false when the session changed meanwhile.
+func (r *SessionRepository) UpdateStatus(id string, from []model.SessionStatus, to model.SessionStatus, at time.Time) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE sessions SET status = $2, updated_at = $3 WHERE id = $1 AND status = ANY($4)
+	`, id, to, at, pq.Array(sessionStatuses(from)))
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+
+	return count > 0, err
+}
+
+// ExpireStale expires the sessions that are not over yet and started, or
+// were posted unscheduled, before startedBefore, returning their IDs.
+func (r *SessionRepository) ExpireStale(startedBefore, at time.Time) ([]string, error) {
+	live := []model.SessionStatus{model.SessionOpen, model.SessionFull, model.SessionInProgress}
+
+	rows, err := r.db.Query(`
+		UPDATE sessions SET status = $1, updated_at = $2
+		WHERE status = ANY($3) AND `+sessionStart+` < $4
+		RETURNING id
+	`, model.SessionExpired, at, pq.Array(sessionStatuses(live)), startedBefore)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ids := []string{}
+
+	for rows.Next() {
+		var id string
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func sessionStatuses(statuses []model.SessionStatus) []string {
+	values := make([]string, len(statuses))
+	for i, s := range statuses {
+		values[i] = string(s)
+	}
+
+	return values
+}
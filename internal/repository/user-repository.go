@@ -3,7 +3,9 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/mauFade/playzy/internal/model"
 )
 
@@ -12,7 +14,34 @@ type UserRepositoryInterface interface {
 	FindByEmail(email string) (*model.UserModel, error)
 	FindByPhone(phone string) (*model.UserModel, error)
 	FindByGamertag(gamertag string) (*model.UserModel, error)
+	FindByIDs(ids []string) ([]*model.UserModel, error)
 	Create(user *model.UserModel) error
+	UpdateLastSeen(id string, at time.Time) error
+}
+
+// userColumns lists the columns read by scanUser, in order.
+const userColumns = "id, name, email, phone, password, gamertag, is_deleted, deleted_at, updated_at, created_at, last_seen_at"
+
+func scanUser(row rowScanner) (*model.UserModel, error) {
+	var user model.UserModel
+
+	if err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Phone,
+		&user.Password,
+		&user.Gamertag,
+		&user.Deleted,
+		&user.DeletedAt,
+		&user.UpdatedAt,
+		&user.CreatedAt,
+		&user.LastSeenAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
 }
 
 type UserRepository struct {
@@ -20,13 +49,9 @@ type UserRepository struct {
 }
 
 func NewUserRepository(d *sql.DB) *UserRepository {
-	r := &UserRepository{
+	return &UserRepository{
 		db: d,
 	}
-
-	r.db.Exec("CREATE TABLE IF NOT EXISTS users (id UUID PRIMARY KEY, name VARCHAR NOT NULL, email VARCHAR NOT NULL, phone VARCHAR NOT NULL, password VARCHAR NOT NULL, gamertag VARCHAR NOT NULL, is_deleted BOOLEAN NOT NULL, deleted_at TIMESTAMP NULL, updated_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL)")
-
-	return r
 }
 
 func (r *UserRepository) Create(user *model.UserModel) error {
@@ -51,23 +76,12 @@ func (r *UserRepository) Create(user *model.UserModel) error {
 }
 
 func (r *UserRepository) FindByID(id string) (*model.UserModel, error) {
-	query := "SELECT * FROM users WHERE id = $1"
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1"
 	row := r.db.QueryRow(query, id)
 
-	var user model.UserModel
+	user, err := scanUser(row)
 
-	if err := row.Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Phone,
-		&user.Password,
-		&user.Gamertag,
-		&user.Deleted,
-		&user.DeletedAt,
-		&user.UpdatedAt,
-		&user.CreatedAt,
-	); err != nil {
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -75,27 +89,16 @@ func (r *UserRepository) FindByID(id string) (*model.UserModel, error) {
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (r *UserRepository) FindByEmail(email string) (*model.UserModel, error) {
-	query := "SELECT * FROM users WHERE email = $1"
+	query := "SELECT " + userColumns + " FROM users WHERE email = $1"
 	row := r.db.QueryRow(query, email)
 
-	var user model.UserModel
+	user, err := scanUser(row)
 
-	if err := row.Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Phone,
-		&user.Password,
-		&user.Gamertag,
-		&user.Deleted,
-		&user.DeletedAt,
-		&user.UpdatedAt,
-		&user.CreatedAt,
-	); err != nil {
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -103,27 +106,16 @@ func (r *UserRepository) FindByEmail(email string) (*model.UserModel, error) {
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (r *UserRepository) FindByGamertag(gamertag string) (*model.UserModel, error) {
-	query := "SELECT * FROM users WHERE gamertag = $1"
+	query := "SELECT " + userColumns + " FROM users WHERE gamertag = $1"
 	row := r.db.QueryRow(query, gamertag)
 
-	var user model.UserModel
+	user, err := scanUser(row)
 
-	if err := row.Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Phone,
-		&user.Password,
-		&user.Gamertag,
-		&user.Deleted,
-		&user.DeletedAt,
-		&user.UpdatedAt,
-		&user.CreatedAt,
-	); err != nil {
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -131,27 +123,16 @@ func (r *UserRepository) FindByGamertag(gamertag string) (*model.UserModel, erro
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (r *UserRepository) FindByPhone(phone string) (*model.UserModel, error) {
-	query := "SELECT * FROM users WHERE phone = $1"
+	query := "SELECT " + userColumns + " FROM users WHERE phone = $1"
 	row := r.db.QueryRow(query, phone)
 
-	var user model.UserModel
+	user, err := scanUser(row)
 
-	if err := row.Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Phone,
-		&user.Password,
-		&user.Gamertag,
-		&user.Deleted,
-		&user.DeletedAt,
-		&user.UpdatedAt,
-		&user.CreatedAt,
-	); err != nil {
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -159,5 +140,39 @@ func (r *UserRepository) FindByPhone(phone string) (*model.UserModel, error) {
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
+}
+
+func (r *UserRepository) FindByIDs(ids []string) ([]*model.UserModel, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE id = ANY($1)"
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users := []*model.UserModel{}
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// UpdateLastSeen moves last_seen_at forward; older timestamps are ignored so
+// updates arriving out of order can't rewind it.
+func (r *UserRepository) UpdateLastSeen(id string, at time.Time) error {
+	_, err := r.db.Exec("UPDATE users SET last_seen_at = $2 WHERE id = $1 AND (last_seen_at IS NULL OR last_seen_at < $2)", id, at)
+
+	return err
 }
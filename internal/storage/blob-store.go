@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound is returned when no blob is stored under a key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files by key. Keys are slash-separated relative
+// paths such as "attachments/<id>"; the filesystem implementation is the
+// default, and S3-compatible stores can be plugged in behind the same
+// interface.
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
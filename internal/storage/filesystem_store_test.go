@@ -0,0 +1,38 @@
+package storage_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mauFade/playzy/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSystemStorePutOpenDelete(t *testing.T) {
+	s, err := storage.NewFileSystemStore(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Put("attachments/abc", strings.NewReader("scoreboard")))
+
+	r, err := s.Open("attachments/abc")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "scoreboard", string(content))
+
+	assert.NoError(t, s.Delete("attachments/abc"))
+	assert.NoError(t, s.Delete("attachments/abc"))
+
+	_, err = s.Open("attachments/abc")
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+}
+
+func TestFileSystemStoreRejectsKeysOutsideRoot(t *testing.T) {
+	s, err := storage.NewFileSystemStore(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", "a/../../b", "a//b"} {
+		assert.Error(t, s.Put(key, strings.NewReader("x")), key)
+	}
+}
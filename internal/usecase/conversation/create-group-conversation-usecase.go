@@ -0,0 +1,64 @@
+package conversation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// maxGroupNameLength caps the name shown for a group chat.
+const maxGroupNameLength = 100
+
+type CreateGroupConversationUseCase struct {
+	cr repository.ConversationRepositoryInterface
+}
+
+// CreateGroupConversationRequest starts a group chat. The creator is always
+// made a member.
+type CreateGroupConversationRequest struct {
+	CreatorID string
+	Name      string
+	MemberIDs []string
+}
+
+func NewCreateGroupConversationUseCase(cr repository.ConversationRepositoryInterface) *CreateGroupConversationUseCase {
+	return &CreateGroupConversationUseCase{
+		cr: cr,
+	}
+}
+
+func (uc *CreateGroupConversationUseCase) Execute(data *CreateGroupConversationRequest) (*model.Conversation, error) {
+	name := strings.TrimSpace(data.Name)
+
+	if name == "" {
+		return nil, ErrMissingName
+	}
+
+	if len([]rune(name)) > maxGroupNameLength {
+		return nil, ErrNameTooLong
+	}
+
+	members := []string{data.CreatorID}
+
+	for _, id := range data.MemberIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMemberID, id)
+		}
+
+		if !slices.Contains(members, id) {
+			members = append(members, id)
+		}
+	}
+
+	c := model.NewGroupConversation(name)
+
+	if err := uc.cr.Create(c, members); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,110 @@
+package conversation_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockConversationRepository struct {
+	mock.Mock
+}
+
+func (m *MockConversationRepository) Create(c *model.Conversation, memberIDs []string) error {
+	args := m.Called(c, memberIDs)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) FindByID(id string) (*model.Conversation, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindDirect(userID, otherUserID string) (*model.Conversation, error) {
+	args := m.Called(userID, otherUserID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindOrCreateDirect(userID, otherUserID string) (*model.Conversation, error) {
+	args := m.Called(userID, otherUserID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) ListMemberIDs(conversationID string) ([]string, error) {
+	args := m.Called(conversationID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindBySessionID(sessionID string) (*model.Conversation, error) {
+	args := m.Called(sessionID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) AddMember(conversationID, userID string) error {
+	args := m.Called(conversationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) RemoveMember(conversationID, userID string) error {
+	args := m.Called(conversationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) Archive(conversationID string, at time.Time) error {
+	args := m.Called(conversationID, at)
+	return args.Error(0)
+}
+
+func TestCreateGroupConversationUseCaseExecuteSuccess(t *testing.T) {
+	cr := new(MockConversationRepository)
+	uc := conversation.NewCreateGroupConversationUseCase(cr)
+
+	creator := uuid.NewString()
+	friend := uuid.NewString()
+
+	cr.On("Create", mock.MatchedBy(func(c *model.Conversation) bool {
+		return c.IsGroup() && c.Name == "Ranked squad"
+	}), []string{creator, friend}).Return(nil).Once()
+
+	c, err := uc.Execute(&conversation.CreateGroupConversationRequest{
+		CreatorID: creator,
+		Name:      " Ranked squad ",
+		MemberIDs: []string{friend, creator, friend},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.ConversationGroup, c.GetKind())
+	cr.AssertExpectations(t)
+}
+
+func TestCreateGroupConversationUseCaseExecuteValidation(t *testing.T) {
+	cr := new(MockConversationRepository)
+	uc := conversation.NewCreateGroupConversationUseCase(cr)
+
+	_, err := uc.Execute(&conversation.CreateGroupConversationRequest{CreatorID: uuid.NewString(), Name: "  "})
+	assert.ErrorIs(t, err, conversation.ErrMissingName)
+
+	_, err = uc.Execute(&conversation.CreateGroupConversationRequest{CreatorID: uuid.NewString(), Name: "squad", MemberIDs: []string{"nope"}})
+	assert.ErrorIs(t, err, conversation.ErrInvalidMemberID)
+	assert.EqualError(t, err, "invalid member id: nope")
+
+	cr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateGroupConversationUseCaseExecuteRepositoryError(t *testing.T) {
+	cr := new(MockConversationRepository)
+	uc := conversation.NewCreateGroupConversationUseCase(cr)
+
+	cr.On("Create", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+
+	c, err := uc.Execute(&conversation.CreateGroupConversationRequest{CreatorID: uuid.NewString(), Name: "squad"})
+
+	assert.Error(t, err)
+	assert.Nil(t, c)
+}
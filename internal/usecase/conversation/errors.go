@@ -0,0 +1,9 @@
+package conversation
+
+import "errors"
+
+var (
+	ErrMissingName     = errors.New("name is required")
+	ErrNameTooLong     = errors.New("name is too long")
+	ErrInvalidMemberID = errors.New("invalid member id")
+)
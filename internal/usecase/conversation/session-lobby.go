@@ -0,0 +1,98 @@
+package conversation
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+var (
+	ErrLobbyNotFound = errors.New("session has no lobby chat")
+	ErrLobbyArchived = errors.New("session lobby chat is archived")
+)
+
+// SessionLobby keeps the group chat of an LFG session in step with the
+// session: it is opened with the owner when the session is posted, accepted
+// players join it, removed players leave it, and it turns read-only once the
+// session closes.
+type SessionLobby struct {
+	cr repository.ConversationRepositoryInterface
+}
+
+func NewSessionLobby(cr repository.ConversationRepositoryInterface) *SessionLobby {
+	return &SessionLobby{
+		cr: cr,
+	}
+}
+
+// Open creates the lobby chat of a new session with its owner as the only
+// member.
+func (l *SessionLobby) Open(s *model.SessionModel) (*model.Conversation, error) {
+	name := []rune(s.GetGame() + " · " + s.GetObjective())
+	if len(name) > maxGroupNameLength {
+		name = name[:maxGroupNameLength]
+	}
+
+	c := model.NewGroupConversation(string(name))
+	c.SessionID = s.GetID().String()
+
+	if err := l.cr.Create(c, []string{s.GetUserID().String()}); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Join adds a player accepted into the session to its lobby chat.
+func (l *SessionLobby) Join(sessionID, userID string) error {
+	c, err := l.find(sessionID)
+
+	if err != nil {
+		return err
+	}
+
+	if c.IsArchived() {
+		return ErrLobbyArchived
+	}
+
+	return l.cr.AddMember(c.ID.String(), userID)
+}
+
+// Leave removes a player who left or was removed from the session.
+func (l *SessionLobby) Leave(sessionID, userID string) error {
+	c, err := l.find(sessionID)
+
+	if err != nil {
+		return err
+	}
+
+	return l.cr.RemoveMember(c.ID.String(), userID)
+}
+
+// Archive keeps the history of a closed session readable but stops new
+// messages.
+func (l *SessionLobby) Archive(sessionID string) error {
+	c, err := l.find(sessionID)
+
+	if err != nil {
+		return err
+	}
+
+	return l.cr.Archive(c.ID.String(), time.Now())
+}
+
+func (l *SessionLobby) find(sessionID string) (*model.Conversation, error) {
+	c, err := l.cr.FindBySessionID(sessionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c == nil {
+		return nil, ErrLobbyNotFound
+	}
+
+	return c, nil
+}
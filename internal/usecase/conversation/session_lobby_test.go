@@ -0,0 +1,71 @@
+package conversation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/conversation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSessionLobbyOpen(t *testing.T) {
+	cr := new(MockConversationRepository)
+	lobby := conversation.NewSessionLobby(cr)
+
+	s := model.NewSessionModel(uuid.New(), uuid.New(), "Valorant", "Climb to Diamond", nil, false, time.Now(), time.Now())
+
+	cr.On("Create", mock.MatchedBy(func(c *model.Conversation) bool {
+		return c.IsGroup() && c.SessionID == s.ID.String() && c.Name == "Valorant · Climb to Diamond"
+	}), []string{s.UserID.String()}).Return(nil).Once()
+
+	c, err := lobby.Open(s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, s.ID.String(), c.GetSessionID())
+	cr.AssertExpectations(t)
+}
+
+func TestSessionLobbyJoinAndLeave(t *testing.T) {
+	cr := new(MockConversationRepository)
+	lobby := conversation.NewSessionLobby(cr)
+
+	room := &model.Conversation{ID: uuid.New(), Kind: model.ConversationGroup, SessionID: "session-1"}
+	cr.On("FindBySessionID", "session-1").Return(room, nil)
+	cr.On("AddMember", room.ID.String(), "player").Return(nil).Once()
+	cr.On("RemoveMember", room.ID.String(), "player").Return(nil).Once()
+
+	assert.NoError(t, lobby.Join("session-1", "player"))
+	assert.NoError(t, lobby.Leave("session-1", "player"))
+	cr.AssertExpectations(t)
+}
+
+func TestSessionLobbyArchive(t *testing.T) {
+	cr := new(MockConversationRepository)
+	lobby := conversation.NewSessionLobby(cr)
+
+	archivedAt := time.Now()
+	room := &model.Conversation{ID: uuid.New(), Kind: model.ConversationGroup, SessionID: "session-1"}
+	cr.On("FindBySessionID", "session-1").Return(room, nil).Once()
+	cr.On("Archive", room.ID.String(), mock.Anything).Return(nil).Once()
+
+	assert.NoError(t, lobby.Archive("session-1"))
+
+	room.ArchivedAt = &archivedAt
+	cr.On("FindBySessionID", "session-1").Return(room, nil).Once()
+
+	assert.ErrorIs(t, lobby.Join("session-1", "late"), conversation.ErrLobbyArchived)
+	cr.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything)
+}
+
+func TestSessionLobbyWithoutRoom(t *testing.T) {
+	cr := new(MockConversationRepository)
+	lobby := conversation.NewSessionLobby(cr)
+
+	cr.On("FindBySessionID", "legacy").Return((*model.Conversation)(nil), nil)
+
+	assert.ErrorIs(t, lobby.Join("legacy", "player"), conversation.ErrLobbyNotFound)
+	assert.ErrorIs(t, lobby.Archive("legacy"), conversation.ErrLobbyNotFound)
+}
@@ -0,0 +1,138 @@
+package message
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// maxEmojiLength is the longest reaction accepted, in bytes. It fits the
+// longest ZWJ emoji sequences.
+const maxEmojiLength = 32
+
+type AddReactionUseCase struct {
+	mr repository.MessageRepositoryInterface
+	rr repository.ReactionRepositoryInterface
+	cr repository.ConversationRepositoryInterface
+}
+
+type ReactionRequest struct {
+	UserID    string
+	MessageID string
+	Emoji     string
+}
+
+// ReactionChange is the outcome of adding or removing a reaction, with the
+// updated counts of the message.
+type ReactionChange struct {
+	MessageID string              `json:"messageId"`
+	UserID    string              `json:"userId"`
+	Emoji     string              `json:"emoji"`
+	Reactions []dto.ReactionCount `json:"reactions"`
+	// Changed is false when the reaction was already in the requested state.
+	Changed bool `json:"-"`
+	// Participants are the users of the conversation the message belongs to.
+	Participants []string `json:"-"`
+}
+
+func NewAddReactionUseCase(mr repository.MessageRepositoryInterface, rr repository.ReactionRepositoryInterface, cr repository.ConversationRepositoryInterface) *AddReactionUseCase {
+	return &AddReactionUseCase{
+		mr: mr,
+		rr: rr,
+		cr: cr,
+	}
+}
+
+// Execute reacts to a message on behalf of one of the conversation
+// participants. Reacting twice with the same emoji is a no-op.
+func (uc *AddReactionUseCase) Execute(data *ReactionRequest) (*ReactionChange, error) {
+	found, err := findReactableMessage(uc.mr, uc.cr, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := uc.rr.Add(&model.Reaction{
+		MessageID: found.Message.ID,
+		UserID:    data.UserID,
+		Emoji:     data.Emoji,
+		CreatedAt: time.Now(),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reactionChange(uc.rr, found, data, changed)
+}
+
+func findReactableMessage(mr repository.MessageRepositoryInterface, cr repository.ConversationRepositoryInterface, data *ReactionRequest) (*MessageChange, error) {
+	data.Emoji = strings.TrimSpace(data.Emoji)
+
+	if !isEmoji(data.Emoji) {
+		return nil, ErrInvalidEmoji
+	}
+
+	found, err := findConversationMessage(mr, cr, data.UserID, data.MessageID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if found.Message.IsDeleted() {
+		return nil, ErrMessageDeleted
+	}
+
+	return found, nil
+}
+
+func reactionChange(rr repository.ReactionRepositoryInterface, found *MessageChange, data *ReactionRequest, changed bool) (*ReactionChange, error) {
+	m := found.Message
+	counts, err := rr.CountByMessages([]string{m.ID.String()})
+
+	if err != nil {
+		return nil, err
+	}
+
+	reactions := counts[m.ID.String()]
+	if reactions == nil {
+		reactions = []dto.ReactionCount{}
+	}
+
+	return &ReactionChange{
+		MessageID:    m.ID.String(),
+		UserID:       data.UserID,
+		Emoji:        data.Emoji,
+		Reactions:    reactions,
+		Changed:      changed,
+		Participants: found.Participants,
+	}, nil
+}
+
+// isEmoji accepts a single short emoji, including keycaps, flags and ZWJ
+// sequences. Plain text and whitespace are rejected.
+func isEmoji(s string) bool {
+	if s == "" || len(s) > maxEmojiLength || !utf8.ValidString(s) {
+		return false
+	}
+
+	hasSymbol := false
+
+	for _, r := range s {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || unicode.IsLetter(r) {
+			return false
+		}
+
+		// U+20E3 is the keycap combining mark, as in 1️⃣
+		if unicode.Is(unicode.So, r) || r == '⃣' {
+			hasSymbol = true
+		}
+	}
+
+	return hasSymbol
+}
@@ -0,0 +1,111 @@
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockReactionRepository) Add(reaction *model.Reaction) (bool, error) {
+	args := m.Called(reaction)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReactionRepository) Remove(messageID, userID, emoji string) (bool, error) {
+	args := m.Called(messageID, userID, emoji)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReactionRepository) CountByMessages(messageIDs []string) (map[string][]dto.ReactionCount, error) {
+	args := m.Called(messageIDs)
+	return args.Get(0).(map[string][]dto.ReactionCount), args.Error(1)
+}
+
+func noReactions() *MockReactionRepository {
+	rr := new(MockReactionRepository)
+	rr.On("CountByMessages", mock.Anything).Return(map[string][]dto.ReactionCount{}, nil).Maybe()
+	return rr
+}
+
+func TestAddReactionUseCaseExecuteSuccess(t *testing.T) {
+	mr := new(MockMessageRepository)
+	rr := new(MockReactionRepository)
+	uc := message.NewAddReactionUseCase(mr, rr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2", Timestamp: time.Now()}
+	counts := []dto.ReactionCount{{Emoji: "👍", Count: 1, UserIDs: []string{"user-2"}}}
+
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+	rr.On("Add", mock.MatchedBy(func(r *model.Reaction) bool {
+		return r.MessageID == m.ID && r.UserID == "user-2" && r.Emoji == "👍"
+	})).Return(true, nil).Once()
+	rr.On("CountByMessages", []string{m.ID.String()}).Return(map[string][]dto.ReactionCount{m.ID.String(): counts}, nil).Once()
+
+	res, err := uc.Execute(&message.ReactionRequest{UserID: "user-2", MessageID: m.ID.String(), Emoji: " 👍 "})
+
+	assert.NoError(t, err)
+	assert.True(t, res.Changed)
+	assert.Equal(t, "👍", res.Emoji)
+	assert.Equal(t, counts, res.Reactions)
+	assert.Equal(t, []string{"user-1", "user-2"}, res.Participants)
+	mr.AssertExpectations(t)
+	rr.AssertExpectations(t)
+}
+
+func TestAddReactionUseCaseExecuteRejectsOutsiders(t *testing.T) {
+	mr := new(MockMessageRepository)
+	rr := new(MockReactionRepository)
+	uc := message.NewAddReactionUseCase(mr, rr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2"}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	_, err := uc.Execute(&message.ReactionRequest{UserID: "user-3", MessageID: m.ID.String(), Emoji: "👍"})
+
+	assert.ErrorIs(t, err, message.ErrMessageNotFound)
+	rr.AssertNotCalled(t, "Add", mock.Anything)
+}
+
+func TestAddReactionUseCaseExecuteRejectsInvalidEmoji(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewAddReactionUseCase(mr, new(MockReactionRepository), new(MockConversationRepository))
+
+	for _, emoji := range []string{"", "lol", "👍 👍", "1", "<script>"} {
+		_, err := uc.Execute(&message.ReactionRequest{UserID: "user-1", MessageID: uuid.NewString(), Emoji: emoji})
+		assert.ErrorIs(t, err, message.ErrInvalidEmoji, emoji)
+	}
+
+	for _, emoji := range []string{"🔥", "👍🏽", "🇧🇷", "1️⃣", "👨‍👩‍👧‍👦"} {
+		mr.On("FindByID", mock.Anything).Return((*model.Message)(nil), nil).Once()
+		_, err := uc.Execute(&message.ReactionRequest{UserID: "user-1", MessageID: uuid.NewString(), Emoji: emoji})
+		assert.ErrorIs(t, err, message.ErrMessageNotFound, emoji)
+	}
+}
+
+func TestRemoveReactionUseCaseExecuteNothingToRemove(t *testing.T) {
+	mr := new(MockMessageRepository)
+	rr := new(MockReactionRepository)
+	uc := message.NewRemoveReactionUseCase(mr, rr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2"}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+	rr.On("Remove", m.ID.String(), "user-1", "👍").Return(false, nil).Once()
+	rr.On("CountByMessages", []string{m.ID.String()}).Return(map[string][]dto.ReactionCount{}, nil).Once()
+
+	res, err := uc.Execute(&message.ReactionRequest{UserID: "user-1", MessageID: m.ID.String(), Emoji: "👍"})
+
+	assert.NoError(t, err)
+	assert.False(t, res.Changed)
+	assert.Empty(t, res.Reactions)
+	rr.AssertExpectations(t)
+}
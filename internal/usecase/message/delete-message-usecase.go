@@ -0,0 +1,48 @@
+package message
+
+import (
+	"time"
+
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+type DeleteMessageUseCase struct {
+	mr repository.MessageRepositoryInterface
+	cr repository.ConversationRepositoryInterface
+}
+
+type DeleteMessageRequest struct {
+	UserID    string
+	MessageID string
+}
+
+func NewDeleteMessageUseCase(mr repository.MessageRepositoryInterface, cr repository.ConversationRepositoryInterface) *DeleteMessageUseCase {
+	return &DeleteMessageUseCase{
+		mr: mr,
+		cr: cr,
+	}
+}
+
+// Execute deletes a message for everyone. It is kept as a tombstone so the
+// conversation still shows where it was. Deleting it again is a no-op.
+func (uc *DeleteMessageUseCase) Execute(data *DeleteMessageRequest) (*MessageChange, error) {
+	change, err := findOwnMessage(uc.mr, uc.cr, data.UserID, data.MessageID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if change.Message.IsDeleted() {
+		return change, nil
+	}
+
+	change.Message.Tombstone(time.Now())
+
+	if err := uc.mr.SoftDelete(change.Message); err != nil {
+		return nil, err
+	}
+
+	change.Changed = true
+
+	return change, nil
+}
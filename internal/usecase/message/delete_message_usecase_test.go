@@ -0,0 +1,58 @@
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteMessageUseCaseExecuteTombstones(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewDeleteMessageUseCase(mr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "discord.gg/secret", SenderID: "user-1", ReceiverID: "user-2", Timestamp: time.Now().Add(-24 * time.Hour)}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+	mr.On("SoftDelete", m).Return(nil).Once()
+
+	res, err := uc.Execute(&message.DeleteMessageRequest{UserID: "user-1", MessageID: m.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Empty(t, res.Message.Content)
+	assert.True(t, res.Message.IsDeleted())
+	assert.True(t, res.Changed)
+	mr.AssertExpectations(t)
+}
+
+func TestDeleteMessageUseCaseExecuteAlreadyDeleted(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewDeleteMessageUseCase(mr, new(MockConversationRepository))
+
+	deletedAt := time.Now()
+	m := &model.Message{ID: uuid.New(), SenderID: "user-1", ReceiverID: "user-2", DeletedAt: &deletedAt}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	res, err := uc.Execute(&message.DeleteMessageRequest{UserID: "user-1", MessageID: m.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &deletedAt, res.Message.DeletedAt)
+	assert.False(t, res.Changed)
+	mr.AssertNotCalled(t, "SoftDelete", mock.Anything)
+}
+
+func TestDeleteMessageUseCaseExecuteRejectsReceiver(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewDeleteMessageUseCase(mr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2"}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	_, err := uc.Execute(&message.DeleteMessageRequest{UserID: "user-2", MessageID: m.ID.String()})
+
+	assert.ErrorIs(t, err, message.ErrNotMessageSender)
+	mr.AssertNotCalled(t, "SoftDelete", mock.Anything)
+}
@@ -0,0 +1,77 @@
+package message
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// EditWindow is how long after sending a message its sender can still edit it.
+const EditWindow = 15 * time.Minute
+
+type EditMessageUseCase struct {
+	mr repository.MessageRepositoryInterface
+	cr repository.ConversationRepositoryInterface
+}
+
+type EditMessageRequest struct {
+	UserID    string
+	MessageID string
+	Content   string
+}
+
+func NewEditMessageUseCase(mr repository.MessageRepositoryInterface, cr repository.ConversationRepositoryInterface) *EditMessageUseCase {
+	return &EditMessageUseCase{
+		mr: mr,
+		cr: cr,
+	}
+}
+
+// Execute replaces the content of a message sent by the user. The previous
+// content is kept in the edit history.
+func (uc *EditMessageUseCase) Execute(data *EditMessageRequest) (*MessageChange, error) {
+	content := strings.TrimSpace(data.Content)
+
+	if content == "" {
+		return nil, ErrEmptyContent
+	}
+
+	change, err := findOwnMessage(uc.mr, uc.cr, data.UserID, data.MessageID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m := change.Message
+
+	if m.IsDeleted() {
+		return nil, ErrMessageDeleted
+	}
+
+	now := time.Now()
+
+	if now.Sub(m.Timestamp) > EditWindow {
+		return nil, ErrEditWindowExpired
+	}
+
+	if m.Content == content {
+		return change, nil
+	}
+
+	previous := m.Content
+	m.Edit(content, now)
+
+	if err := uc.mr.Edit(m, previous); err != nil {
+		if errors.Is(err, repository.ErrMessageDeleted) {
+			return nil, ErrMessageDeleted
+		}
+
+		return nil, err
+	}
+
+	change.Changed = true
+
+	return change, nil
+}
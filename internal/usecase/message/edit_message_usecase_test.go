@@ -0,0 +1,133 @@
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEditMessageUseCaseExecuteSuccess(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewEditMessageUseCase(mr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2", Timestamp: time.Now()}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+	mr.On("Edit", mock.Anything, "gg").Return(nil).Once()
+
+	res, err := uc.Execute(&message.EditMessageRequest{UserID: "user-1", MessageID: m.ID.String(), Content: " gg wp "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "gg wp", res.Message.Content)
+	assert.NotNil(t, res.Message.EditedAt)
+	assert.True(t, res.Changed)
+	mr.AssertExpectations(t)
+}
+
+func TestEditMessageUseCaseExecuteSameContent(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewEditMessageUseCase(mr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2", Timestamp: time.Now()}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	res, err := uc.Execute(&message.EditMessageRequest{UserID: "user-1", MessageID: m.ID.String(), Content: "gg"})
+
+	assert.NoError(t, err)
+	assert.False(t, res.Changed)
+	mr.AssertNotCalled(t, "Edit", mock.Anything, mock.Anything)
+}
+
+func TestEditMessageUseCaseExecuteDeletedMeanwhile(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewEditMessageUseCase(mr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2", Timestamp: time.Now()}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+	mr.On("Edit", mock.Anything, "gg").Return(repository.ErrMessageDeleted).Once()
+
+	res, err := uc.Execute(&message.EditMessageRequest{UserID: "user-1", MessageID: m.ID.String(), Content: "gg wp"})
+
+	assert.ErrorIs(t, err, message.ErrMessageDeleted)
+	assert.Nil(t, res)
+}
+
+func TestEditMessageUseCaseExecuteRejectsOthersMessages(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewEditMessageUseCase(mr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2", Timestamp: time.Now()}
+	mr.On("FindByID", m.ID.String()).Return(m, nil)
+
+	_, err := uc.Execute(&message.EditMessageRequest{UserID: "user-2", MessageID: m.ID.String(), Content: "ez"})
+	assert.ErrorIs(t, err, message.ErrNotMessageSender)
+
+	_, err = uc.Execute(&message.EditMessageRequest{UserID: "user-3", MessageID: m.ID.String(), Content: "ez"})
+	assert.ErrorIs(t, err, message.ErrMessageNotFound)
+
+	mr.AssertNotCalled(t, "Edit", mock.Anything, mock.Anything)
+}
+
+func TestEditMessageUseCaseExecuteWindowExpired(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewEditMessageUseCase(mr, new(MockConversationRepository))
+
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ReceiverID: "user-2", Timestamp: time.Now().Add(-message.EditWindow - time.Minute)}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	_, err := uc.Execute(&message.EditMessageRequest{UserID: "user-1", MessageID: m.ID.String(), Content: "ez"})
+
+	assert.ErrorIs(t, err, message.ErrEditWindowExpired)
+	mr.AssertNotCalled(t, "Edit", mock.Anything, mock.Anything)
+}
+
+func TestEditMessageUseCaseExecuteDeletedMessage(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewEditMessageUseCase(mr, new(MockConversationRepository))
+
+	deletedAt := time.Now()
+	m := &model.Message{ID: uuid.New(), SenderID: "user-1", ReceiverID: "user-2", Timestamp: time.Now(), DeletedAt: &deletedAt}
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	_, err := uc.Execute(&message.EditMessageRequest{UserID: "user-1", MessageID: m.ID.String(), Content: "ez"})
+
+	assert.ErrorIs(t, err, message.ErrMessageDeleted)
+}
+
+func TestEditMessageUseCaseExecuteInvalidInput(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewEditMessageUseCase(mr, new(MockConversationRepository))
+
+	_, err := uc.Execute(&message.EditMessageRequest{UserID: "user-1", MessageID: uuid.NewString(), Content: "  "})
+	assert.ErrorIs(t, err, message.ErrEmptyContent)
+
+	_, err = uc.Execute(&message.EditMessageRequest{UserID: "user-1", MessageID: "nope", Content: "ez"})
+	assert.ErrorIs(t, err, message.ErrInvalidMessageID)
+
+	mr.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+func TestEditMessageUseCaseExecuteGroupMessage(t *testing.T) {
+	mr := new(MockMessageRepository)
+	cr := new(MockConversationRepository)
+	uc := message.NewEditMessageUseCase(mr, cr)
+
+	conversationID := uuid.NewString()
+	m := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "user-1", ConversationID: conversationID, Timestamp: time.Now()}
+	mr.On("FindByID", m.ID.String()).Return(m, nil)
+	cr.On("ListMemberIDs", conversationID).Return([]string{"user-1", "user-2", "user-3"}, nil)
+	mr.On("Edit", m, "gg").Return(nil).Once()
+
+	res, err := uc.Execute(&message.EditMessageRequest{UserID: "user-1", MessageID: m.ID.String(), Content: "gg wp"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user-1", "user-2", "user-3"}, res.Participants)
+
+	_, err = uc.Execute(&message.EditMessageRequest{UserID: "outsider", MessageID: m.ID.String(), Content: "ez"})
+	assert.ErrorIs(t, err, message.ErrMessageNotFound)
+}
@@ -0,0 +1,20 @@
+package message
+
+import "errors"
+
+var (
+	ErrInvalidMessageID     = errors.New("invalid message id")
+	ErrEmptyContent         = errors.New("message content is required")
+	ErrMessageNotFound      = errors.New("message not found")
+	ErrNotMessageSender     = errors.New("only the sender can change this message")
+	ErrEditWindowExpired    = errors.New("message can no longer be edited")
+	ErrMessageDeleted       = errors.New("message was deleted")
+	ErrInvalidEmoji         = errors.New("reaction must be an emoji")
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrEmptyAttachment      = errors.New("attachment file is required")
+	ErrAttachmentTooLarge   = errors.New("attachment is too large")
+	ErrUnsupportedFileType  = errors.New("attachment file type is not supported")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrConflictingCursors   = errors.New("before and after can't be used together")
+	ErrInvalidSearchQuery   = errors.New("search query must have between 1 and 200 characters")
+)
@@ -0,0 +1,113 @@
+package message
+
+import (
+	"errors"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/storage"
+)
+
+type GetAttachmentUseCase struct {
+	ar    repository.AttachmentRepositoryInterface
+	mr    repository.MessageRepositoryInterface
+	cr    repository.ConversationRepositoryInterface
+	store storage.BlobStore
+}
+
+// GetAttachmentRequest reads an attachment, or its thumbnail when Thumbnail is
+// set.
+type GetAttachmentRequest struct {
+	UserID       string
+	AttachmentID string
+	Thumbnail    bool
+}
+
+// AttachmentContent is an open attachment. The caller must close Body.
+type AttachmentContent struct {
+	Attachment  *model.Attachment
+	ContentType string
+	Body        io.ReadCloser
+}
+
+func NewGetAttachmentUseCase(ar repository.AttachmentRepositoryInterface, mr repository.MessageRepositoryInterface, cr repository.ConversationRepositoryInterface, store storage.BlobStore) *GetAttachmentUseCase {
+	return &GetAttachmentUseCase{
+		ar:    ar,
+		mr:    mr,
+		cr:    cr,
+		store: store,
+	}
+}
+
+// Execute opens the file for its uploader or for the participants of the
+// conversation it was shared in. Everyone else, and files of deleted
+// messages, get ErrAttachmentNotFound.
+func (uc *GetAttachmentUseCase) Execute(data *GetAttachmentRequest) (*AttachmentContent, error) {
+	if _, err := uuid.Parse(data.AttachmentID); err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	a, err := uc.ar.FindByID(data.AttachmentID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if a == nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	if err := uc.checkAccess(a, data.UserID); err != nil {
+		return nil, err
+	}
+
+	key, contentType := a.StorageKey, a.ContentType
+
+	if data.Thumbnail {
+		if !a.HasThumbnail {
+			return nil, ErrAttachmentNotFound
+		}
+
+		key, contentType = a.ThumbnailKey, "image/jpeg"
+	}
+
+	body, err := uc.store.Open(key)
+
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttachmentContent{Attachment: a, ContentType: contentType, Body: body}, nil
+}
+
+func (uc *GetAttachmentUseCase) checkAccess(a *model.Attachment, userID string) error {
+	if !a.IsSent() {
+		if a.UploaderID != userID {
+			return ErrAttachmentNotFound
+		}
+
+		return nil
+	}
+
+	found, err := findConversationMessage(uc.mr, uc.cr, userID, a.MessageID)
+
+	if errors.Is(err, ErrMessageNotFound) || errors.Is(err, ErrInvalidMessageID) {
+		return ErrAttachmentNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if found.Message.IsDeleted() {
+		return ErrAttachmentNotFound
+	}
+
+	return nil
+}
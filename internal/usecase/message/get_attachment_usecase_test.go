@@ -0,0 +1,97 @@
+package message_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func sentAttachment(messageID string) *model.Attachment {
+	id := uuid.New()
+	a := &model.Attachment{
+		ID:          id,
+		UploaderID:  "user-1",
+		MessageID:   messageID,
+		FileName:    "scoreboard.png",
+		ContentType: "image/png",
+		StorageKey:  "attachments/" + id.String(),
+	}
+	a.SetThumbnailKey("thumbnails/" + id.String() + ".jpg")
+	return a
+}
+
+func TestGetAttachmentUseCaseServesConversationParticipants(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	mr := new(MockMessageRepository)
+	store := memoryStore{}
+	uc := message.NewGetAttachmentUseCase(ar, mr, nil, store)
+
+	m := &model.Message{ID: uuid.New(), SenderID: "user-1", ReceiverID: "user-2"}
+	a := sentAttachment(m.ID.String())
+	store[a.ThumbnailKey] = []byte("thumb")
+
+	ar.On("FindByID", a.ID.String()).Return(a, nil).Once()
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	content, err := uc.Execute(&message.GetAttachmentRequest{UserID: "user-2", AttachmentID: a.ID.String(), Thumbnail: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", content.ContentType)
+	body, _ := io.ReadAll(content.Body)
+	assert.Equal(t, "thumb", string(body))
+}
+
+func TestGetAttachmentUseCaseHidesFilesFromOutsiders(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	mr := new(MockMessageRepository)
+	uc := message.NewGetAttachmentUseCase(ar, mr, nil, memoryStore{})
+
+	m := &model.Message{ID: uuid.New(), SenderID: "user-1", ReceiverID: "user-2"}
+	a := sentAttachment(m.ID.String())
+
+	ar.On("FindByID", a.ID.String()).Return(a, nil).Once()
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	_, err := uc.Execute(&message.GetAttachmentRequest{UserID: "user-3", AttachmentID: a.ID.String()})
+
+	assert.ErrorIs(t, err, message.ErrAttachmentNotFound)
+}
+
+func TestGetAttachmentUseCaseHidesFilesOfDeletedMessages(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	mr := new(MockMessageRepository)
+	uc := message.NewGetAttachmentUseCase(ar, mr, nil, memoryStore{})
+
+	m := &model.Message{ID: uuid.New(), SenderID: "user-1", ReceiverID: "user-2"}
+	m.Tombstone(time.Now())
+	a := sentAttachment(m.ID.String())
+
+	ar.On("FindByID", a.ID.String()).Return(a, nil).Once()
+	mr.On("FindByID", m.ID.String()).Return(m, nil).Once()
+
+	_, err := uc.Execute(&message.GetAttachmentRequest{UserID: "user-1", AttachmentID: a.ID.String()})
+
+	assert.ErrorIs(t, err, message.ErrAttachmentNotFound)
+}
+
+func TestGetAttachmentUseCaseUnsentFilesOnlyForUploader(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	store := memoryStore{}
+	uc := message.NewGetAttachmentUseCase(ar, new(MockMessageRepository), nil, store)
+
+	a := sentAttachment("")
+	store[a.StorageKey] = []byte("png")
+	ar.On("FindByID", a.ID.String()).Return(a, nil)
+
+	content, err := uc.Execute(&message.GetAttachmentRequest{UserID: "user-1", AttachmentID: a.ID.String()})
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", content.ContentType)
+
+	_, err = uc.Execute(&message.GetAttachmentRequest{UserID: "user-2", AttachmentID: a.ID.String()})
+	assert.ErrorIs(t, err, message.ErrAttachmentNotFound)
+}
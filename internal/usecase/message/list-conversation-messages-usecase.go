@@ -0,0 +1,62 @@
+package message
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+type ListConversationMessagesUseCase struct {
+	mr repository.MessageRepositoryInterface
+	rr repository.ReactionRepositoryInterface
+	cr repository.ConversationRepositoryInterface
+	ar repository.AttachmentRepositoryInterface
+}
+
+// ListConversationMessagesRequest reads a page of a conversation the user is a
+// member of, with the same cursors as ListUsersMessagesRequest.
+type ListConversationMessagesRequest struct {
+	UserID         string
+	ConversationID string
+	Limit          int
+	Before         string
+	After          string
+}
+
+func NewListConversationMessagesUseCase(mr repository.MessageRepositoryInterface, rr repository.ReactionRepositoryInterface, cr repository.ConversationRepositoryInterface, ar repository.AttachmentRepositoryInterface) *ListConversationMessagesUseCase {
+	return &ListConversationMessagesUseCase{
+		mr: mr,
+		rr: rr,
+		cr: cr,
+		ar: ar,
+	}
+}
+
+func (uc *ListConversationMessagesUseCase) Execute(data *ListConversationMessagesRequest) (*ListUsersMessagesResponse, error) {
+	if _, err := uuid.Parse(data.ConversationID); err != nil {
+		return nil, ErrConversationNotFound
+	}
+
+	members, err := uc.cr.ListMemberIDs(data.ConversationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Non-members can't tell a conversation exists
+	if !slices.Contains(members, data.UserID) {
+		return nil, ErrConversationNotFound
+	}
+
+	return listMessages(uc.mr, uc.rr, uc.ar, &ListUsersMessagesRequest{
+		UserID: data.UserID,
+		Limit:  data.Limit,
+		Before: data.Before,
+		After:  data.After,
+	}, func(page dto.MessagePage) ([]model.Message, error) {
+		return uc.mr.ListByConversation(data.ConversationID, page)
+	})
+}
@@ -0,0 +1,73 @@
+package message
+
+import (
+	"math"
+
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+const (
+	defaultInboxPageSize = 20
+	maxInboxPageSize     = 50
+)
+
+type ListInboxUseCase struct {
+	mr repository.MessageRepositoryInterface
+	rr repository.ReactionRepositoryInterface
+}
+
+type ListInboxRequest struct {
+	UserID string
+	Page   int
+	Limit  int
+}
+
+func NewListInboxUseCase(mr repository.MessageRepositoryInterface, rr repository.ReactionRepositoryInterface) *ListInboxUseCase {
+	return &ListInboxUseCase{
+		mr: mr,
+		rr: rr,
+	}
+}
+
+func (uc *ListInboxUseCase) Execute(data *ListInboxRequest) (*dto.InboxPageResponse, error) {
+	page := data.Page
+	if page < 1 {
+		page = 1
+	}
+
+	limit := data.Limit
+	if limit <= 0 {
+		limit = defaultInboxPageSize
+	}
+	if limit > maxInboxPageSize {
+		limit = maxInboxPageSize
+	}
+
+	entries, total, err := uc.mr.ListInbox(data.UserID, limit, (page-1)*limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.LastMessageID.String())
+	}
+
+	reactions, err := uc.rr.CountByMessages(ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].LastMessageReactions = reactions[entries[i].LastMessageID.String()]
+	}
+
+	return &dto.InboxPageResponse{
+		Page:          page,
+		TotalPages:    int(math.Ceil(float64(total) / float64(limit))),
+		Conversations: entries,
+	}, nil
+}
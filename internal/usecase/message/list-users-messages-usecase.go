@@ -2,69 +2,283 @@ package message
 
 import (
 	"log"
+	"slices"
 	"time"
 
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
 	"github.com/mauFade/playzy/internal/repository"
 )
 
+const (
+	defaultMessagesPageSize = 50
+	maxMessagesPageSize     = 100
+
+	// quotePreviewLength is how many characters of a replied-to message are
+	// quoted, matching the inbox preview.
+	quotePreviewLength = 100
+)
+
 type ListUsersMessagesUseCase struct {
 	mr repository.MessageRepositoryInterface
+	rr repository.ReactionRepositoryInterface
+	ar repository.AttachmentRepositoryInterface
 }
 
+// ListUsersMessagesRequest reads the latest messages of a conversation, or the
+// ones older than Before / newer than After. Cursors are the opaque tokens
+// returned in a previous response.
 type ListUsersMessagesRequest struct {
 	UserID      string
 	OtherUserID string
 	Limit       int
-	Offset      int
+	Before      string
+	After       string
 }
 
 type MessageResponse struct {
-	ID         string    `json:"id"`
-	Content    string    `json:"content"`
-	SenderID   string    `json:"senderId"`
-	ReceiverID string    `json:"receiverId"`
-	Timestamp  time.Time `json:"timestamp"`
-	IsRead     bool      `json:"isRead"`
-	IsMine     bool      `json:"isMine"`
+	ID         string     `json:"id"`
+	Content    string     `json:"content"`
+	SenderID   string     `json:"senderId"`
+	ReceiverID string     `json:"receiverId"`
+	Timestamp  time.Time  `json:"timestamp"`
+	IsRead     bool       `json:"isRead"`
+	ReadAt     *time.Time `json:"readAt,omitempty"`
+	EditedAt   *time.Time `json:"editedAt,omitempty"`
+	DeletedAt  *time.Time `json:"deletedAt,omitempty"`
+	IsMine     bool       `json:"isMine"`
+	// Reactions aggregates the emojis both participants reacted with.
+	Reactions []dto.ReactionCount `json:"reactions"`
+	// ReplyTo quotes the message this one answers.
+	ReplyTo *QuotedMessage `json:"replyTo,omitempty"`
+	// Attachments are the files shared in the message.
+	Attachments []model.Attachment `json:"attachments"`
 }
 
-func NewListUsersMessagesUseCase(mr repository.MessageRepositoryInterface) *ListUsersMessagesUseCase {
+// QuotedMessage is a compact preview of the message a reply answers.
+type QuotedMessage struct {
+	ID       string    `json:"id"`
+	SenderID string    `json:"senderId"`
+	Preview  string    `json:"preview"`
+	SentAt   time.Time `json:"sentAt"`
+	Deleted  bool      `json:"deleted,omitempty"`
+}
+
+// ListUsersMessagesResponse holds a page in chronological order. Before loads
+// older messages and is empty once the start of the conversation is reached;
+// After loads messages newer than this page.
+type ListUsersMessagesResponse struct {
+	Messages []MessageResponse `json:"messages"`
+	Before   string            `json:"before,omitempty"`
+	After    string            `json:"after,omitempty"`
+}
+
+func NewListUsersMessagesUseCase(mr repository.MessageRepositoryInterface, rr repository.ReactionRepositoryInterface, ar repository.AttachmentRepositoryInterface) *ListUsersMessagesUseCase {
 	return &ListUsersMessagesUseCase{
 		mr: mr,
+		rr: rr,
+		ar: ar,
 	}
 }
 
-func (uc *ListUsersMessagesUseCase) Execute(data *ListUsersMessagesRequest) ([]MessageResponse, error) {
-	ms, err := uc.mr.List(data.UserID, data.OtherUserID, data.Limit, data.Offset)
+func (uc *ListUsersMessagesUseCase) Execute(data *ListUsersMessagesRequest) (*ListUsersMessagesResponse, error) {
+	resp, err := listMessages(uc.mr, uc.rr, uc.ar, data, func(page dto.MessagePage) ([]model.Message, error) {
+		return uc.mr.List(data.UserID, data.OtherUserID, page)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Atualizar mensagens como lidas (se o usuário for o destinatário)
+	if err := uc.mr.SetMessagesIsRead(data.UserID, data.OtherUserID); err != nil {
+		log.Printf("Erro ao marcar mensagens como lidas: %v", err)
+	}
+
+	return resp, nil
+}
+
+// listMessages loads one page of messages through fetch and decorates it with
+// reactions, attachments and quoted parents. It is shared by one-to-one and
+// group history.
+func listMessages(mr repository.MessageRepositoryInterface, rr repository.ReactionRepositoryInterface, ar repository.AttachmentRepositoryInterface, data *ListUsersMessagesRequest, fetch func(dto.MessagePage) ([]model.Message, error)) (*ListUsersMessagesResponse, error) {
+	if data.Before != "" && data.After != "" {
+		return nil, ErrConflictingCursors
+	}
+
+	limit := data.Limit
+	if limit <= 0 {
+		limit = defaultMessagesPageSize
+	}
+	if limit > maxMessagesPageSize {
+		limit = maxMessagesPageSize
+	}
+
+	// Fetch one extra message to know whether there is more to load
+	page := dto.MessagePage{Limit: limit + 1}
+
+	var err error
+
+	if data.Before != "" {
+		if page.Before, err = dto.DecodeCursor(data.Before); err != nil {
+			return nil, err
+		}
+	}
+
+	if data.After != "" {
+		if page.After, err = dto.DecodeCursor(data.After); err != nil {
+			return nil, err
+		}
+	}
+
+	ms, err := fetch(page)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var messages []MessageResponse
+	hasMore := len(ms) > limit
+	hasOlder := page.After != nil
+
+	if hasMore {
+		if page.After != nil {
+			// Newer messages remain after this page
+			ms = ms[:limit]
+		} else {
+			ms = ms[1:]
+			hasOlder = true
+		}
+	}
+
+	resp := &ListUsersMessagesResponse{
+		Messages: []MessageResponse{},
+		After:    data.After,
+	}
+
+	ids := make([]string, 0, len(ms))
 	for _, m := range ms {
-		var msg MessageResponse
+		ids = append(ids, m.ID.String())
+	}
 
-		msg.ID = m.ID.String()
-		msg.Content = m.Content
-		msg.SenderID = m.SenderID
-		msg.ReceiverID = m.ReceiverID
-		msg.Timestamp = m.Timestamp
-		msg.IsRead = m.IsRead
-		msg.IsMine = (m.SenderID == data.UserID)
+	reactions, err := rr.CountByMessages(ids)
 
-		messages = append(messages, msg)
+	if err != nil {
+		return nil, err
 	}
 
-	// Atualizar mensagens como lidas (se o usuário for o destinatário)
-	// go func() {
-	err = uc.mr.SetMessagesIsRead(data.UserID, data.OtherUserID)
+	attachments, err := ar.ListByMessages(ids)
 
-	log.Printf("Erro ao marcar mensagens como lidas: %v", err)
 	if err != nil {
-		log.Printf("Erro ao marcar mensagens como lidas: %v", err)
+		return nil, err
+	}
+
+	parents, err := findParents(mr, ms)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range ms {
+		r := toMessageResponse(m, data.UserID)
+
+		if counts, ok := reactions[r.ID]; ok && !m.IsDeleted() {
+			r.Reactions = counts
+		}
+
+		if files, ok := attachments[r.ID]; ok && !m.IsDeleted() {
+			r.Attachments = files
+		}
+
+		if parent, ok := parents[m.ReplyToID]; ok && m.InConversationOf(&parent) {
+			r.ReplyTo = quote(parent)
+		}
+
+		resp.Messages = append(resp.Messages, r)
+	}
+
+	if len(ms) > 0 {
+		if hasOlder {
+			resp.Before = dto.EncodeCursor(cursorOf(ms[0]))
+		}
+		resp.After = dto.EncodeCursor(cursorOf(ms[len(ms)-1]))
+	}
+
+	return resp, nil
+}
+
+// findParents loads the messages answered by the page, keyed by ID. Parents
+// that are on the page already aren't fetched again.
+func findParents(mr repository.MessageRepositoryInterface, ms []model.Message) (map[string]model.Message, error) {
+	parents := make(map[string]model.Message)
+	onPage := make(map[string]model.Message, len(ms))
+
+	for _, m := range ms {
+		onPage[m.ID.String()] = m
+	}
+
+	missing := []string{}
+
+	for _, m := range ms {
+		if !m.IsReply() {
+			continue
+		}
+
+		if parent, ok := onPage[m.ReplyToID]; ok {
+			parents[m.ReplyToID] = parent
+		} else if _, seen := parents[m.ReplyToID]; !seen && !slices.Contains(missing, m.ReplyToID) {
+			missing = append(missing, m.ReplyToID)
+		}
+	}
+
+	if len(missing) == 0 {
+		return parents, nil
+	}
+
+	found, err := mr.FindByIDs(missing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, parent := range found {
+		parents[parent.ID.String()] = parent
 	}
-	// }()
 
-	return messages, nil
+	return parents, nil
+}
+
+func quote(m model.Message) *QuotedMessage {
+	preview := []rune(m.Content)
+	if len(preview) > quotePreviewLength {
+		preview = preview[:quotePreviewLength]
+	}
+
+	return &QuotedMessage{
+		ID:       m.ID.String(),
+		SenderID: m.SenderID,
+		Preview:  string(preview),
+		SentAt:   m.Timestamp,
+		Deleted:  m.IsDeleted(),
+	}
+}
+
+func cursorOf(m model.Message) dto.MessageCursor {
+	return dto.MessageCursor{CreatedAt: m.Timestamp, ID: m.ID}
+}
+
+func toMessageResponse(m model.Message, userID string) MessageResponse {
+	return MessageResponse{
+		ID:          m.ID.String(),
+		Content:     m.Content,
+		SenderID:    m.SenderID,
+		ReceiverID:  m.ReceiverID,
+		Timestamp:   m.Timestamp,
+		IsRead:      m.IsRead,
+		ReadAt:      m.ReadAt,
+		EditedAt:    m.EditedAt,
+		DeletedAt:   m.DeletedAt,
+		IsMine:      m.SenderID == userID,
+		Reactions:   []dto.ReactionCount{},
+		Attachments: []model.Attachment{},
+	}
 }
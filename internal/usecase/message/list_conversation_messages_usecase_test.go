@@ -0,0 +1,101 @@
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockConversationRepository struct {
+	mock.Mock
+}
+
+func (m *MockConversationRepository) Create(c *model.Conversation, memberIDs []string) error {
+	args := m.Called(c, memberIDs)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) FindByID(id string) (*model.Conversation, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindDirect(userID, otherUserID string) (*model.Conversation, error) {
+	args := m.Called(userID, otherUserID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindOrCreateDirect(userID, otherUserID string) (*model.Conversation, error) {
+	args := m.Called(userID, otherUserID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) ListMemberIDs(conversationID string) ([]string, error) {
+	args := m.Called(conversationID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindBySessionID(sessionID string) (*model.Conversation, error) {
+	args := m.Called(sessionID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) AddMember(conversationID, userID string) error {
+	args := m.Called(conversationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) RemoveMember(conversationID, userID string) error {
+	args := m.Called(conversationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) Archive(conversationID string, at time.Time) error {
+	args := m.Called(conversationID, at)
+	return args.Error(0)
+}
+
+func TestListConversationMessagesUseCaseForMember(t *testing.T) {
+	mr := new(MockMessageRepository)
+	cr := new(MockConversationRepository)
+	uc := message.NewListConversationMessagesUseCase(mr, noReactions(), cr, noAttachments())
+
+	conversationID := uuid.NewString()
+	ms := conversation(2)
+	for i := range ms {
+		ms[i].ReceiverID = ""
+		ms[i].ConversationID = conversationID
+	}
+
+	cr.On("ListMemberIDs", conversationID).Return([]string{"user-1", "user-2", "user-3"}, nil).Once()
+	mr.On("ListByConversation", conversationID, dto.MessagePage{Limit: 51}).Return(ms, nil).Once()
+
+	res, err := uc.Execute(&message.ListConversationMessagesRequest{UserID: "user-3", ConversationID: conversationID})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Messages, 2)
+	assert.False(t, res.Messages[0].IsMine)
+	mr.AssertExpectations(t)
+	mr.AssertNotCalled(t, "SetMessagesIsRead", mock.Anything, mock.Anything)
+}
+
+func TestListConversationMessagesUseCaseRejectsNonMembers(t *testing.T) {
+	mr := new(MockMessageRepository)
+	cr := new(MockConversationRepository)
+	uc := message.NewListConversationMessagesUseCase(mr, noReactions(), cr, noAttachments())
+
+	conversationID := uuid.NewString()
+	cr.On("ListMemberIDs", conversationID).Return([]string{"user-1", "user-2"}, nil).Once()
+
+	res, err := uc.Execute(&message.ListConversationMessagesRequest{UserID: "outsider", ConversationID: conversationID})
+
+	assert.ErrorIs(t, err, message.ErrConversationNotFound)
+	assert.Nil(t, res)
+	mr.AssertNotCalled(t, "ListByConversation", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,134 @@
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageRepository) Create(msg *model.Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) FindByClientID(senderID, clientID string) (*model.Message, error) {
+	args := m.Called(senderID, clientID)
+	return args.Get(0).(*model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindByID(id string) (*model.Message, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) List(fstUserId, scdUserId string, page dto.MessagePage) ([]model.Message, error) {
+	args := m.Called(fstUserId, scdUserId, page)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) SetMessagesIsRead(userId, otherUserID string) error {
+	args := m.Called(userId, otherUserID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) MarkReadUpTo(readerID, senderID string, upTo, readAt time.Time) (int64, error) {
+	args := m.Called(readerID, senderID, upTo, readAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListConversationPartners(userID string) ([]string, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListInbox(userID string, limit, offset int) ([]dto.InboxEntry, int, error) {
+	args := m.Called(userID, limit, offset)
+	return args.Get(0).([]dto.InboxEntry), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) Edit(msg *model.Message, previousContent string) error {
+	args := m.Called(msg, previousContent)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) SoftDelete(msg *model.Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) FindByIDs(ids []string) ([]model.Message, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListByConversation(conversationID string, page dto.MessagePage) ([]model.Message, error) {
+	args := m.Called(conversationID, page)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) Search(userID, query string, before *dto.MessageCursor, limit int) ([]dto.MessageSearchHit, error) {
+	args := m.Called(userID, query, before, limit)
+	return args.Get(0).([]dto.MessageSearchHit), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListMissed(userID string, since *dto.MessageCursor, limit int) ([]model.Message, error) {
+	args := m.Called(userID, since, limit)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) MarkDelivered(receiverID string, ids []string, at time.Time) error {
+	args := m.Called(receiverID, ids, at)
+	return args.Error(0)
+}
+
+func TestListInboxUseCaseExecuteSuccess(t *testing.T) {
+	mr := new(MockMessageRepository)
+	rr := new(MockReactionRepository)
+	uc := message.NewListInboxUseCase(mr, rr)
+
+	lastMessageID := uuid.New()
+	partnerID := uuid.New()
+	entries := []dto.InboxEntry{
+		{ConversationID: uuid.New(), Kind: "direct", PartnerID: &partnerID, PartnerGamertag: "gamer123", LastMessageID: lastMessageID, LastMessagePreview: "gg", UnreadCount: 2},
+	}
+	reactions := []dto.ReactionCount{{Emoji: "🔥", Count: 1, UserIDs: []string{"user-1"}}}
+	mr.On("ListInbox", "user-1", 10, 10).Return(entries, 21, nil).Once()
+	rr.On("CountByMessages", []string{lastMessageID.String()}).Return(map[string][]dto.ReactionCount{
+		lastMessageID.String(): reactions,
+	}, nil).Once()
+
+	res, err := uc.Execute(&message.ListInboxRequest{UserID: "user-1", Page: 2, Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res.Page)
+	assert.Equal(t, 3, res.TotalPages)
+	assert.Equal(t, entries, res.Conversations)
+	assert.Equal(t, reactions, res.Conversations[0].LastMessageReactions)
+	mr.AssertExpectations(t)
+	rr.AssertExpectations(t)
+}
+
+func TestListInboxUseCaseExecuteDefaults(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewListInboxUseCase(mr, noReactions())
+
+	mr.On("ListInbox", "user-1", 20, 0).Return([]dto.InboxEntry{}, 0, nil).Once()
+
+	res, err := uc.Execute(&message.ListInboxRequest{UserID: "user-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Page)
+	assert.Equal(t, 0, res.TotalPages)
+	assert.Empty(t, res.Conversations)
+	mr.AssertExpectations(t)
+}
@@ -0,0 +1,195 @@
+package message_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func conversation(n int) []model.Message {
+	start := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	ms := make([]model.Message, n)
+
+	for i := range ms {
+		ms[i] = model.Message{
+			ID:         uuid.New(),
+			Content:    "msg",
+			SenderID:   "user-1",
+			ReceiverID: "user-2",
+			Timestamp:  start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+
+	return ms
+}
+
+func TestListUsersMessagesUseCaseLatestPage(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewListUsersMessagesUseCase(mr, noReactions(), noAttachments())
+
+	ms := conversation(4)
+	mr.On("List", "user-1", "user-2", dto.MessagePage{Limit: 4}).Return(ms, nil).Once()
+	mr.On("SetMessagesIsRead", "user-1", "user-2").Return(nil).Once()
+
+	res, err := uc.Execute(&message.ListUsersMessagesRequest{UserID: "user-1", OtherUserID: "user-2", Limit: 3})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Messages, 3)
+	assert.Equal(t, ms[1].ID.String(), res.Messages[0].ID)
+	assert.Equal(t, ms[3].ID.String(), res.Messages[2].ID)
+	assert.True(t, res.Messages[0].IsMine)
+
+	before, err := dto.DecodeCursor(res.Before)
+	assert.NoError(t, err)
+	assert.Equal(t, ms[1].ID, before.ID)
+	assert.True(t, ms[1].Timestamp.Equal(before.CreatedAt))
+
+	after, err := dto.DecodeCursor(res.After)
+	assert.NoError(t, err)
+	assert.Equal(t, ms[3].ID, after.ID)
+	mr.AssertExpectations(t)
+}
+
+func TestListUsersMessagesUseCaseBeforeReachesStart(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewListUsersMessagesUseCase(mr, noReactions(), noAttachments())
+
+	ms := conversation(3)
+	cursor := dto.MessageCursor{CreatedAt: ms[2].Timestamp, ID: ms[2].ID}
+
+	mr.On("List", "user-1", "user-2", dto.MessagePage{Before: &cursor, Limit: 4}).Return(ms[:2], nil).Once()
+	mr.On("SetMessagesIsRead", "user-1", "user-2").Return(nil).Once()
+
+	res, err := uc.Execute(&message.ListUsersMessagesRequest{
+		UserID:      "user-1",
+		OtherUserID: "user-2",
+		Limit:       3,
+		Before:      dto.EncodeCursor(cursor),
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Messages, 2)
+	assert.Empty(t, res.Before)
+	assert.NotEmpty(t, res.After)
+	mr.AssertExpectations(t)
+}
+
+func TestListUsersMessagesUseCaseAfterWithNothingNew(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewListUsersMessagesUseCase(mr, noReactions(), noAttachments())
+
+	token := dto.EncodeCursor(dto.MessageCursor{CreatedAt: time.Now(), ID: uuid.New()})
+
+	mr.On("List", "user-1", "user-2", mock.Anything).Return([]model.Message{}, nil).Once()
+	mr.On("SetMessagesIsRead", "user-1", "user-2").Return(nil).Once()
+
+	res, err := uc.Execute(&message.ListUsersMessagesRequest{UserID: "user-1", OtherUserID: "user-2", After: token})
+
+	assert.NoError(t, err)
+	assert.Empty(t, res.Messages)
+	assert.Equal(t, token, res.After)
+}
+
+func TestListUsersMessagesUseCaseInvalidCursor(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewListUsersMessagesUseCase(mr, noReactions(), noAttachments())
+
+	res, err := uc.Execute(&message.ListUsersMessagesRequest{UserID: "user-1", OtherUserID: "user-2", Before: "garbage"})
+
+	assert.ErrorIs(t, err, dto.ErrInvalidCursor)
+	assert.Nil(t, res)
+	mr.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListUsersMessagesUseCaseConflictingCursors(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewListUsersMessagesUseCase(mr, noReactions(), noAttachments())
+
+	first := conversation(1)[0]
+	cursor := dto.EncodeCursor(dto.MessageCursor{CreatedAt: first.Timestamp, ID: first.ID})
+
+	_, err := uc.Execute(&message.ListUsersMessagesRequest{UserID: "user-1", OtherUserID: "user-2", Before: cursor, After: cursor})
+
+	assert.ErrorIs(t, err, message.ErrConflictingCursors)
+	mr.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListUsersMessagesUseCaseIncludesReactions(t *testing.T) {
+	mr := new(MockMessageRepository)
+	rr := new(MockReactionRepository)
+	uc := message.NewListUsersMessagesUseCase(mr, rr, noAttachments())
+
+	ms := conversation(2)
+	deletedAt := time.Now()
+	ms[1].Tombstone(deletedAt)
+
+	counts := []dto.ReactionCount{{Emoji: "🔥", Count: 2, UserIDs: []string{"user-1", "user-2"}}}
+	mr.On("List", "user-1", "user-2", mock.Anything).Return(ms, nil).Once()
+	mr.On("SetMessagesIsRead", "user-1", "user-2").Return(nil).Once()
+	rr.On("CountByMessages", []string{ms[0].ID.String(), ms[1].ID.String()}).Return(map[string][]dto.ReactionCount{
+		ms[0].ID.String(): counts,
+		ms[1].ID.String(): counts,
+	}, nil).Once()
+
+	res, err := uc.Execute(&message.ListUsersMessagesRequest{UserID: "user-1", OtherUserID: "user-2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, counts, res.Messages[0].Reactions)
+	assert.Empty(t, res.Messages[1].Reactions)
+	rr.AssertExpectations(t)
+}
+
+func TestListUsersMessagesUseCaseQuotesParents(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewListUsersMessagesUseCase(mr, noReactions(), noAttachments())
+
+	ms := conversation(2)
+	old := model.Message{ID: uuid.New(), Content: strings.Repeat("what time? ", 20), SenderID: "user-2", ReceiverID: "user-1"}
+	ms[0].ReplyToID = old.ID.String()
+	ms[1].ReplyToID = ms[0].ID.String()
+
+	mr.On("List", "user-1", "user-2", mock.Anything).Return(ms, nil).Once()
+	mr.On("FindByIDs", []string{old.ID.String()}).Return([]model.Message{old}, nil).Once()
+	mr.On("SetMessagesIsRead", "user-1", "user-2").Return(nil).Once()
+
+	res, err := uc.Execute(&message.ListUsersMessagesRequest{UserID: "user-1", OtherUserID: "user-2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, old.ID.String(), res.Messages[0].ReplyTo.ID)
+	assert.Equal(t, "user-2", res.Messages[0].ReplyTo.SenderID)
+	assert.Len(t, []rune(res.Messages[0].ReplyTo.Preview), 100)
+	assert.Equal(t, ms[0].ID.String(), res.Messages[1].ReplyTo.ID)
+	assert.Equal(t, "msg", res.Messages[1].ReplyTo.Preview)
+	mr.AssertExpectations(t)
+}
+
+func TestListUsersMessagesUseCaseIncludesAttachments(t *testing.T) {
+	mr := new(MockMessageRepository)
+	ar := new(MockAttachmentRepository)
+	uc := message.NewListUsersMessagesUseCase(mr, noReactions(), ar)
+
+	ms := conversation(2)
+	ms[1].Tombstone(time.Now())
+
+	files := []model.Attachment{{ID: uuid.New(), MessageID: ms[0].ID.String(), FileName: "loadout.png"}}
+	mr.On("List", "user-1", "user-2", mock.Anything).Return(ms, nil).Once()
+	mr.On("SetMessagesIsRead", "user-1", "user-2").Return(nil).Once()
+	ar.On("ListByMessages", []string{ms[0].ID.String(), ms[1].ID.String()}).Return(map[string][]model.Attachment{
+		ms[0].ID.String(): files,
+		ms[1].ID.String(): files,
+	}, nil).Once()
+
+	res, err := uc.Execute(&message.ListUsersMessagesRequest{UserID: "user-1", OtherUserID: "user-2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, files, res.Messages[0].Attachments)
+	assert.Empty(t, res.Messages[1].Attachments)
+	ar.AssertExpectations(t)
+}
@@ -0,0 +1,77 @@
+package message
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// MessageChange is an edited or deleted message along with the users of its
+// conversation, who must be notified.
+type MessageChange struct {
+	Message      *model.Message
+	Participants []string
+	// Changed is false when the message was already in the requested state.
+	Changed bool
+}
+
+// participantsOf returns the users of the conversation a message belongs to.
+func participantsOf(cr repository.ConversationRepositoryInterface, m *model.Message) ([]string, error) {
+	if !m.IsGroup() {
+		return m.Participants(), nil
+	}
+
+	if cr == nil {
+		return []string{}, nil
+	}
+
+	return cr.ListMemberIDs(m.ConversationID)
+}
+
+// findConversationMessage loads a message of a conversation the user is part
+// of, along with the conversation participants. Messages of other
+// conversations are reported as not found.
+func findConversationMessage(mr repository.MessageRepositoryInterface, cr repository.ConversationRepositoryInterface, userID, messageID string) (*MessageChange, error) {
+	if _, err := uuid.Parse(messageID); err != nil {
+		return nil, ErrInvalidMessageID
+	}
+
+	m, err := mr.FindByID(messageID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if m == nil {
+		return nil, ErrMessageNotFound
+	}
+
+	participants, err := participantsOf(cr, m)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(participants, userID) {
+		return nil, ErrMessageNotFound
+	}
+
+	return &MessageChange{Message: m, Participants: participants}, nil
+}
+
+// findOwnMessage loads a message the user sent.
+func findOwnMessage(mr repository.MessageRepositoryInterface, cr repository.ConversationRepositoryInterface, userID, messageID string) (*MessageChange, error) {
+	found, err := findConversationMessage(mr, cr, userID, messageID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if found.Message.SenderID != userID {
+		return nil, ErrNotMessageSender
+	}
+
+	return found, nil
+}
@@ -0,0 +1,37 @@
+package message
+
+import (
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+type RemoveReactionUseCase struct {
+	mr repository.MessageRepositoryInterface
+	rr repository.ReactionRepositoryInterface
+	cr repository.ConversationRepositoryInterface
+}
+
+func NewRemoveReactionUseCase(mr repository.MessageRepositoryInterface, rr repository.ReactionRepositoryInterface, cr repository.ConversationRepositoryInterface) *RemoveReactionUseCase {
+	return &RemoveReactionUseCase{
+		mr: mr,
+		rr: rr,
+		cr: cr,
+	}
+}
+
+// Execute removes a reaction the user put on a message. Removing a reaction
+// that isn't there is a no-op.
+func (uc *RemoveReactionUseCase) Execute(data *ReactionRequest) (*ReactionChange, error) {
+	found, err := findReactableMessage(uc.mr, uc.cr, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := uc.rr.Remove(found.Message.ID.String(), data.UserID, data.Emoji)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reactionChange(uc.rr, found, data, changed)
+}
@@ -0,0 +1,80 @@
+package message
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 50
+	maxSearchQueryLength  = 200
+)
+
+type SearchMessagesUseCase struct {
+	mr repository.MessageRepositoryInterface
+}
+
+// SearchMessagesRequest searches the user's conversations. Before is the
+// cursor returned with a previous page of results.
+type SearchMessagesRequest struct {
+	UserID string
+	Query  string
+	Limit  int
+	Before string
+}
+
+func NewSearchMessagesUseCase(mr repository.MessageRepositoryInterface) *SearchMessagesUseCase {
+	return &SearchMessagesUseCase{
+		mr: mr,
+	}
+}
+
+func (uc *SearchMessagesUseCase) Execute(data *SearchMessagesRequest) (*dto.MessageSearchResponse, error) {
+	query := strings.TrimSpace(data.Query)
+
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	limit := data.Limit
+	if limit <= 0 {
+		limit = defaultSearchPageSize
+	}
+	if limit > maxSearchPageSize {
+		limit = maxSearchPageSize
+	}
+
+	var before *dto.MessageCursor
+
+	if data.Before != "" {
+		var err error
+		if before, err = dto.DecodeCursor(data.Before); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra hit to know whether there is more to load
+	hits, err := uc.mr.Search(data.UserID, query, before, limit+1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.MessageSearchResponse{Results: hits}
+
+	if len(hits) > limit {
+		resp.Results = hits[:limit]
+		last := resp.Results[limit-1]
+		resp.Before = dto.EncodeCursor(dto.MessageCursor{CreatedAt: last.Timestamp, ID: last.ID})
+	}
+
+	for i := range resp.Results {
+		resp.Results[i].IsMine = resp.Results[i].SenderID == data.UserID
+	}
+
+	return resp, nil
+}
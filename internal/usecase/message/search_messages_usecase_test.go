@@ -0,0 +1,74 @@
+package message_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func searchHits(n int) []dto.MessageSearchHit {
+	start := time.Date(2024, 1, 8, 20, 0, 0, 0, time.UTC)
+	hits := make([]dto.MessageSearchHit, n)
+
+	for i := range hits {
+		hits[i] = dto.MessageSearchHit{
+			ID:        uuid.New(),
+			SenderID:  "user-2",
+			Snippet:   "lobby code <mark>XJ42</mark>",
+			Timestamp: start.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+
+	hits[0].SenderID = "user-1"
+
+	return hits
+}
+
+func TestSearchMessagesUseCasePaginatesOlderResults(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewSearchMessagesUseCase(mr)
+
+	hits := searchHits(3)
+	mr.On("Search", "user-1", "XJ42", (*dto.MessageCursor)(nil), 3).Return(hits, nil).Once()
+
+	res, err := uc.Execute(&message.SearchMessagesRequest{UserID: "user-1", Query: "  XJ42 ", Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Results, 2)
+	assert.True(t, res.Results[0].IsMine)
+	assert.False(t, res.Results[1].IsMine)
+
+	before, err := dto.DecodeCursor(res.Before)
+	assert.NoError(t, err)
+	assert.Equal(t, hits[1].ID, before.ID)
+
+	mr.On("Search", "user-1", "XJ42", before, 3).Return(hits[2:], nil).Once()
+
+	res, err = uc.Execute(&message.SearchMessagesRequest{UserID: "user-1", Query: "XJ42", Limit: 2, Before: res.Before})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Results, 1)
+	assert.Empty(t, res.Before)
+	mr.AssertExpectations(t)
+}
+
+func TestSearchMessagesUseCaseRejectsInvalidQueries(t *testing.T) {
+	mr := new(MockMessageRepository)
+	uc := message.NewSearchMessagesUseCase(mr)
+
+	for _, q := range []string{"", "   ", strings.Repeat("a", 201)} {
+		_, err := uc.Execute(&message.SearchMessagesRequest{UserID: "user-1", Query: q})
+		assert.ErrorIs(t, err, message.ErrInvalidSearchQuery)
+	}
+
+	_, err := uc.Execute(&message.SearchMessagesRequest{UserID: "user-1", Query: "gg", Before: "garbage"})
+	assert.ErrorIs(t, err, dto.ErrInvalidCursor)
+
+	mr.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
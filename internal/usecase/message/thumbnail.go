@@ -0,0 +1,99 @@
+package message
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+const (
+	// thumbnailSize bounds the longest side of generated thumbnails.
+	thumbnailSize = 320
+
+	// maxThumbnailSourcePixels keeps huge images, or files that only claim to
+	// be huge, from being decoded into memory.
+	maxThumbnailSourcePixels = 25_000_000
+)
+
+// imageSize returns the dimensions of a PNG, JPEG or GIF without decoding it.
+func imageSize(data []byte) (int, int, bool) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return cfg.Width, cfg.Height, true
+}
+
+// makeThumbnail scales the image down to fit thumbnailSize and encodes it as
+// JPEG. Transparent areas are flattened onto white. It returns false for
+// images that can't be decoded or are too large to.
+func makeThumbnail(data []byte) ([]byte, bool) {
+	w, h, ok := imageSize(data)
+	if !ok || w == 0 || h == 0 || w*h > maxThumbnailSourcePixels {
+		return nil, false
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scaleDown(src, thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, false
+	}
+
+	return buf.Bytes(), true
+}
+
+// scaleDown resizes src to fit a size x size box keeping its aspect ratio,
+// averaging the source pixels covered by each destination pixel. Images that
+// already fit keep their size.
+func scaleDown(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := sw, sh
+
+	if sw > size || sh > size {
+		if sw >= sh {
+			dw, dh = size, max(1, sh*size/sw)
+		} else {
+			dw, dh = max(1, sw*size/sh), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := b.Min.Y+y*sh/dh, b.Min.Y+(y+1)*sh/dh
+
+		for x := 0; x < dw; x++ {
+			x0, x1 := b.Min.X+x*sw/dw, b.Min.X+(x+1)*sw/dw
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			// Colors are alpha-premultiplied, so adding the missing coverage
+			// composites the pixel over white.
+			white := n*0xffff - a
+			dst.Set(x, y, color.RGBA64{
+				R: uint16((r + white) / n),
+				G: uint16((g + white) / n),
+				B: uint16((bl + white) / n),
+				A: 0xffff,
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,155 @@
+package message
+
+import (
+	"bytes"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/storage"
+)
+
+const (
+	// DefaultMaxAttachmentSize is the upload limit when none is configured.
+	DefaultMaxAttachmentSize = 10 << 20
+
+	maxFileNameLength = 255
+)
+
+// allowedAttachmentTypes are the sniffed media types players may share.
+var allowedAttachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+type UploadAttachmentUseCase struct {
+	ar      repository.AttachmentRepositoryInterface
+	store   storage.BlobStore
+	maxSize int64
+}
+
+// UploadAttachmentRequest carries a file read from an upload. FileName is the
+// name given by the client and is only used for display.
+type UploadAttachmentRequest struct {
+	UploaderID string
+	FileName   string
+	Data       []byte
+}
+
+// NewUploadAttachmentUseCase creates the use case. Non-positive sizes keep
+// DefaultMaxAttachmentSize.
+func NewUploadAttachmentUseCase(ar repository.AttachmentRepositoryInterface, store storage.BlobStore, maxSize int64) *UploadAttachmentUseCase {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxAttachmentSize
+	}
+
+	return &UploadAttachmentUseCase{
+		ar:      ar,
+		store:   store,
+		maxSize: maxSize,
+	}
+}
+
+func (uc *UploadAttachmentUseCase) MaxSize() int64 {
+	return uc.maxSize
+}
+
+// Execute stores the file and, for images, a thumbnail. The content type is
+// sniffed from the data; the one declared by the client is ignored.
+func (uc *UploadAttachmentUseCase) Execute(data *UploadAttachmentRequest) (*model.Attachment, error) {
+	if len(data.Data) == 0 {
+		return nil, ErrEmptyAttachment
+	}
+
+	if int64(len(data.Data)) > uc.maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	contentType := http.DetectContentType(data.Data)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil || !allowedAttachmentTypes[mediaType] {
+		return nil, ErrUnsupportedFileType
+	}
+
+	id := uuid.New()
+
+	a := &model.Attachment{
+		ID:          id,
+		UploaderID:  data.UploaderID,
+		FileName:    cleanFileName(data.FileName),
+		ContentType: contentType,
+		Size:        int64(len(data.Data)),
+		StorageKey:  "attachments/" + id.String(),
+	}
+
+	if err := uc.store.Put(a.StorageKey, bytes.NewReader(data.Data)); err != nil {
+		return nil, err
+	}
+
+	if a.IsImage() {
+		a.Width, a.Height, _ = imageSize(data.Data)
+
+		if thumb, ok := makeThumbnail(data.Data); ok {
+			key := "thumbnails/" + id.String() + ".jpg"
+
+			if err := uc.store.Put(key, bytes.NewReader(thumb)); err != nil {
+				log.Printf("Erro ao salvar miniatura do anexo %s: %v", id, err)
+			} else {
+				a.SetThumbnailKey(key)
+			}
+		}
+	}
+
+	if err := uc.ar.Create(a); err != nil {
+		uc.discard(a)
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// discard removes the blobs of an attachment that couldn't be saved.
+func (uc *UploadAttachmentUseCase) discard(a *model.Attachment) {
+	for _, key := range []string{a.StorageKey, a.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+
+		if err := uc.store.Delete(key); err != nil {
+			log.Printf("Erro ao remover arquivo %s: %v", key, err)
+		}
+	}
+}
+
+// cleanFileName keeps the base name of the client's file name without control
+// characters, so it is safe to echo in headers.
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+
+	if runes := []rune(name); len(runes) > maxFileNameLength {
+		name = string(runes[len(runes)-maxFileNameLength:])
+	}
+
+	return name
+}
@@ -0,0 +1,164 @@
+package message_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/storage"
+	"github.com/mauFade/playzy/internal/usecase/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(a *model.Attachment) error {
+	args := m.Called(a)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) FindByID(id string) (*model.Attachment, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByMessages(messageIDs []string) (map[string][]model.Attachment, error) {
+	args := m.Called(messageIDs)
+	return args.Get(0).(map[string][]model.Attachment), args.Error(1)
+}
+
+func noAttachments() *MockAttachmentRepository {
+	ar := new(MockAttachmentRepository)
+	ar.On("ListByMessages", mock.Anything).Return(map[string][]model.Attachment{}, nil).Maybe()
+	return ar
+}
+
+// memoryStore is a BlobStore kept in memory.
+type memoryStore map[string][]byte
+
+func (s memoryStore) Put(key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	s[key] = data
+	return err
+}
+
+func (s memoryStore) Open(key string) (io.ReadCloser, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, storage.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s memoryStore) Delete(key string) error {
+	delete(s, key)
+	return nil
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestUploadAttachmentUseCaseStoresImageWithThumbnail(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	store := memoryStore{}
+	uc := message.NewUploadAttachmentUseCase(ar, store, 0)
+
+	ar.On("Create", mock.Anything).Return(nil).Once()
+
+	a, err := uc.Execute(&message.UploadAttachmentRequest{
+		UploaderID: "user-1",
+		FileName:   "../../loadout.png",
+		Data:       testPNG(t, 1280, 640),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "loadout.png", a.FileName)
+	assert.Equal(t, "image/png", a.ContentType)
+	assert.Equal(t, 1280, a.Width)
+	assert.Equal(t, 640, a.Height)
+	assert.True(t, a.HasThumbnail)
+	assert.Contains(t, store, a.StorageKey)
+
+	thumb, err := jpeg.DecodeConfig(bytes.NewReader(store[a.ThumbnailKey]))
+	assert.NoError(t, err)
+	assert.Equal(t, 320, thumb.Width)
+	assert.Equal(t, 160, thumb.Height)
+	ar.AssertExpectations(t)
+}
+
+func TestUploadAttachmentUseCaseStoresOtherFilesWithoutThumbnail(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	store := memoryStore{}
+	uc := message.NewUploadAttachmentUseCase(ar, store, 0)
+
+	ar.On("Create", mock.Anything).Return(nil).Once()
+
+	a, err := uc.Execute(&message.UploadAttachmentRequest{
+		UploaderID: "user-1",
+		FileName:   "scores.txt",
+		Data:       []byte("GG 13-7"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", a.ContentType)
+	assert.False(t, a.HasThumbnail)
+	assert.Len(t, store, 1)
+}
+
+func TestUploadAttachmentUseCaseSniffsContentType(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	store := memoryStore{}
+	uc := message.NewUploadAttachmentUseCase(ar, store, 0)
+
+	a, err := uc.Execute(&message.UploadAttachmentRequest{
+		UploaderID: "user-1",
+		FileName:   "screenshot.png",
+		Data:       []byte("<html><script>alert(1)</script></html>"),
+	})
+
+	assert.ErrorIs(t, err, message.ErrUnsupportedFileType)
+	assert.Nil(t, a)
+	assert.Empty(t, store)
+	ar.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUploadAttachmentUseCaseEnforcesSizeLimit(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	uc := message.NewUploadAttachmentUseCase(ar, memoryStore{}, 4)
+
+	_, err := uc.Execute(&message.UploadAttachmentRequest{UploaderID: "user-1", Data: []byte("12345")})
+	assert.ErrorIs(t, err, message.ErrAttachmentTooLarge)
+
+	_, err = uc.Execute(&message.UploadAttachmentRequest{UploaderID: "user-1"})
+	assert.ErrorIs(t, err, message.ErrEmptyAttachment)
+}
+
+func TestUploadAttachmentUseCaseDiscardsFilesWhenSaveFails(t *testing.T) {
+	ar := new(MockAttachmentRepository)
+	store := memoryStore{}
+	uc := message.NewUploadAttachmentUseCase(ar, store, 0)
+
+	ar.On("Create", mock.Anything).Return(errors.New("db down")).Once()
+
+	_, err := uc.Execute(&message.UploadAttachmentRequest{UploaderID: "user-1", FileName: "a.png", Data: testPNG(t, 10, 10)})
+
+	assert.Error(t, err)
+	assert.Empty(t, store)
+}
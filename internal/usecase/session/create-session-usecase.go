@@ -1,7 +1,8 @@
 package session
 
 import (
-	"errors"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,9 +10,17 @@ import (
 	"github.com/mauFade/playzy/internal/repository"
 )
 
+const (
+	// DefaultMaxPlayers is a usual squad, used when the owner doesn't say
+	DefaultMaxPlayers = 5
+	minMaxPlayers     = 2
+	maxMaxPlayers     = 100
+)
+
 type CreateSessionUseCase struct {
-	sr repository.SessionRepositoryInterface
-	ur repository.UserRepositoryInterface
+	sr    repository.SessionRepositoryInterface
+	ur    repository.UserRepositoryInterface
+	lobby Lobby
 }
 
 type CreateSessionRequest struct {
@@ -20,30 +29,52 @@ type CreateSessionRequest struct {
 	Objective string
 	Rank      *string
 	IsRanked  bool
+	// MaxPlayers is the size of the party, owner included
+	MaxPlayers int
+	// StartsAt is RFC 3339, or a local date and time in TimeZone; empty
+	// means right away
+	StartsAt string
+	TimeZone string
 }
 
-func NewCreateSessionUseCase(r repository.SessionRepositoryInterface, u repository.UserRepositoryInterface) *CreateSessionUseCase {
+func NewCreateSessionUseCase(r repository.SessionRepositoryInterface, u repository.UserRepositoryInterface, l Lobby) *CreateSessionUseCase {
 	return &CreateSessionUseCase{
-		sr: r,
-		ur: u,
+		sr:    r,
+		ur:    u,
+		lobby: l,
 	}
 }
 
 func (uc *CreateSessionUseCase) Execute(data *CreateSessionRequest) (*model.SessionModel, error) {
-	user, err := uc.ur.FindByID(data.UserID)
+	maxPlayers := data.MaxPlayers
+	if maxPlayers == 0 {
+		maxPlayers = DefaultMaxPlayers
+	}
 
-	if err != nil {
+	if err := checkMaxPlayers(maxPlayers); err != nil {
 		return nil, err
 	}
 
-	if user == nil {
-		return nil, errors.New("user not found with this id")
+	rank := normalizeRank(data.Rank)
+
+	if data.IsRanked && rank == nil {
+		return nil, ErrRankRequired
 	}
 
-	var isRanked = true
+	startsAt, timeZone, err := resolveSchedule(data.StartsAt, data.TimeZone, time.Now())
 
-	if data.Rank == nil {
-		isRanked = false
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.ur.FindByID(data.UserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
 	}
 
 	session := model.NewSessionModel(
@@ -51,17 +82,51 @@ func (uc *CreateSessionUseCase) Execute(data *CreateSessionRequest) (*model.Sess
 		user.GetID(),
 		data.Game,
 		data.Objective,
-		data.Rank,
-		isRanked,
+		rank,
+		data.IsRanked,
 		time.Now(),
 		time.Now(),
 	)
 
+	session.SetMaxPlayers(maxPlayers)
+	session.SetSchedule(startsAt, timeZone)
+
 	err = uc.sr.Create(session)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// A sala de chat é opcional; a sessão continua válida sem ela
+	lobby, err := uc.lobby.Open(session)
+
+	if err != nil {
+		log.Printf("Erro ao abrir o chat da sessão %s: %v", session.GetID(), err)
+	} else {
+		session.SetLobbyID(lobby.GetID().String())
+	}
+
 	return session, nil
 }
+
+func checkMaxPlayers(n int) error {
+	if n < minMaxPlayers || n > maxMaxPlayers {
+		return ErrInvalidMaxPlayers
+	}
+
+	return nil
+}
+
+// normalizeRank trims a rank, treating a blank one as no rank.
+func normalizeRank(rank *string) *string {
+	if rank == nil {
+		return nil
+	}
+
+	r := strings.TrimSpace(*rank)
+	if r == "" {
+		return nil
+	}
+
+	return &r
+}
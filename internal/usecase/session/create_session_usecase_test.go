@@ -1,6 +1,7 @@
 package session_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -28,8 +29,8 @@ func (m *MockCreateSessionRepository) FindByID(id uuid.UUID) (*model.SessionMode
 	return args.Get(0).(*model.SessionModel), args.Error(1)
 }
 
-func (m *MockCreateSessionRepository) FindAvailable(page int, rank, game string) (*dto.SessionsPageResponse, error) {
-	args := m.Called(page)
+func (m *MockCreateSessionRepository) FindAvailable(filter dto.SessionFilter) (*dto.SessionsPageResponse, error) {
+	args := m.Called(filter)
 
 	return args.Get(0).(*dto.SessionsPageResponse), args.Error(1)
 }
@@ -39,6 +40,21 @@ func (m *MockCreateSessionRepository) Delete(id string) error {
 	return args.Error(0)
 }
 
+func (m *MockCreateSessionRepository) Update(s *model.SessionModel) (*model.SessionModel, error) {
+	args := m.Called(s)
+	return args.Get(0).(*model.SessionModel), args.Error(1)
+}
+
+func (m *MockCreateSessionRepository) UpdateStatus(id string, from []model.SessionStatus, to model.SessionStatus, at time.Time) (bool, error) {
+	args := m.Called(id, from, to, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCreateSessionRepository) ExpireStale(createdBefore, at time.Time) ([]string, error) {
+	args := m.Called(createdBefore, at)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 type MockSessionUserRepository struct {
 	mock.Mock
 }
@@ -72,6 +88,40 @@ func (m *MockSessionUserRepository) Create(user *model.UserModel) error {
 	return args.Error(0)
 }
 
+func (m *MockSessionUserRepository) FindByIDs(ids []string) ([]*model.UserModel, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]*model.UserModel), args.Error(1)
+}
+
+func (m *MockSessionUserRepository) UpdateLastSeen(id string, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+type MockLobby struct {
+	mock.Mock
+}
+
+func (m *MockLobby) Open(s *model.SessionModel) (*model.Conversation, error) {
+	args := m.Called(s)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockLobby) Join(sessionID, userID string) error {
+	args := m.Called(sessionID, userID)
+	return args.Error(0)
+}
+
+func (m *MockLobby) Leave(sessionID, userID string) error {
+	args := m.Called(sessionID, userID)
+	return args.Error(0)
+}
+
+func (m *MockLobby) Archive(sessionID string) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
+}
+
 func TestCreateSessionUseCaseExecuteSuccess(t *testing.T) {
 	sr := new(MockCreateSessionRepository)
 	ur := new(MockSessionUserRepository)
@@ -91,10 +141,14 @@ func TestCreateSessionUseCaseExecuteSuccess(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
+	lobbyID := uuid.New()
+
 	ur.On("FindByID", userID.String()).Return(existingUser, nil).Once()
 	sr.On("Create", mock.Anything).Return(nil).Once()
+	lobby := new(MockLobby)
+	lobby.On("Open", mock.Anything).Return(&model.Conversation{ID: lobbyID, Kind: model.ConversationGroup}, nil).Once()
 
-	uc := session.NewCreateSessionUseCase(sr, ur)
+	uc := session.NewCreateSessionUseCase(sr, ur, lobby)
 
 	rank := "Dima"
 
@@ -109,4 +163,61 @@ func TestCreateSessionUseCaseExecuteSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, res)
 	assert.Equal(t, res.UserID.String(), userID.String())
+	assert.Equal(t, lobbyID.String(), res.GetLobbyID())
+	assert.Equal(t, session.DefaultMaxPlayers, res.GetMaxPlayers())
+	assert.Equal(t, model.SessionOpen, res.GetStatus())
+	lobby.AssertExpectations(t)
+}
+
+func TestCreateSessionUseCaseRankedWithoutRank(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	ur := new(MockSessionUserRepository)
+
+	uc := session.NewCreateSessionUseCase(sr, ur, new(MockLobby))
+
+	blank := "  "
+	for _, rank := range []*string{nil, &blank} {
+		_, err := uc.Execute(&session.CreateSessionRequest{UserID: uuid.NewString(), Game: "Game", Objective: "Obj", Rank: rank, IsRanked: true})
+		assert.ErrorIs(t, err, session.ErrRankRequired)
+	}
+
+	ur.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+func TestCreateSessionUseCaseInvalidMaxPlayers(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	ur := new(MockSessionUserRepository)
+
+	uc := session.NewCreateSessionUseCase(sr, ur, new(MockLobby))
+
+	for _, n := range []int{1, 101, -3} {
+		_, err := uc.Execute(&session.CreateSessionRequest{UserID: uuid.NewString(), Game: "Game", Objective: "Obj", MaxPlayers: n})
+		assert.ErrorIs(t, err, session.ErrInvalidMaxPlayers)
+	}
+
+	ur.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+func TestCreateSessionUseCaseExecuteWithoutLobby(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	ur := new(MockSessionUserRepository)
+	lobby := new(MockLobby)
+
+	userID := uuid.New()
+
+	ur.On("FindByID", userID.String()).Return(&model.UserModel{ID: userID}, nil).Once()
+	sr.On("Create", mock.Anything).Return(nil).Once()
+	lobby.On("Open", mock.Anything).Return((*model.Conversation)(nil), errors.New("db down")).Once()
+
+	uc := session.NewCreateSessionUseCase(sr, ur, lobby)
+
+	res, err := uc.Execute(&session.CreateSessionRequest{
+		UserID:    userID.String(),
+		Game:      "Game",
+		Objective: "Obj",
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+	assert.Empty(t, res.GetLobbyID())
 }
@@ -0,0 +1,41 @@
+package session
+
+import (
+	"log"
+
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+type DeleteSessionUseCase struct {
+	sr    repository.SessionRepositoryInterface
+	lobby Lobby
+}
+
+type DeleteSessionRequest struct {
+	UserID    string
+	SessionID string
+}
+
+func NewDeleteSessionUseCase(sr repository.SessionRepositoryInterface, l Lobby) *DeleteSessionUseCase {
+	return &DeleteSessionUseCase{
+		sr:    sr,
+		lobby: l,
+	}
+}
+
+// Execute deletes a session the user posted along with its roster. The lobby
+// chat is kept read-only so players don't lose the history.
+func (uc *DeleteSessionUseCase) Execute(data *DeleteSessionRequest) error {
+	s, err := findOwnSession(uc.sr, data.UserID, data.SessionID)
+
+	if err != nil {
+		return err
+	}
+
+	// The lobby is found through the session, which unlinks it once deleted
+	if err := uc.lobby.Archive(s.GetID().String()); err != nil {
+		log.Printf("Erro ao arquivar o chat da sessão %s: %v", s.GetID(), err)
+	}
+
+	return uc.sr.Delete(s.GetID().String())
+}
@@ -0,0 +1,39 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteSession(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+
+	sr.On("Delete", s.ID.String()).Return(nil).Once()
+	lobby.On("Archive", s.ID.String()).Return(nil).Once().Run(func(mock.Arguments) {
+		sr.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	uc := session.NewDeleteSessionUseCase(sr, lobby)
+
+	assert.NoError(t, uc.Execute(&session.DeleteSessionRequest{UserID: s.UserID.String(), SessionID: s.ID.String()}))
+	sr.AssertExpectations(t)
+	lobby.AssertExpectations(t)
+}
+
+func TestDeleteSessionOwnerOnly(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+
+	uc := session.NewDeleteSessionUseCase(sr, new(MockLobby))
+
+	err := uc.Execute(&session.DeleteSessionRequest{UserID: uuid.NewString(), SessionID: s.ID.String()})
+
+	assert.ErrorIs(t, err, session.ErrNotSessionOwner)
+	sr.AssertNotCalled(t, "Delete", mock.Anything)
+}
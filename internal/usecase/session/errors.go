@@ -0,0 +1,31 @@
+package session
+
+import "errors"
+
+var (
+	ErrInvalidSessionID  = errors.New("invalid session id")
+	ErrInvalidUserID     = errors.New("invalid user id")
+	ErrSessionNotFound   = errors.New("session not found")
+	ErrUserNotFound      = errors.New("user not found with this id")
+	ErrNotSessionOwner   = errors.New("only the session owner can do this")
+	ErrOwnerCannotJoin   = errors.New("the owner is already in the session")
+	ErrOwnerCannotLeave  = errors.New("the owner can't leave their own session")
+	ErrAlreadyRequested  = errors.New("already asked to join this session")
+	ErrJoinRefused       = errors.New("the owner turned down this player")
+	ErrMemberNotFound    = errors.New("member not found")
+	ErrRequestNotPending = errors.New("join request was already answered")
+	ErrInvalidMaxPlayers = errors.New("max players must be between 2 and 100")
+	ErrSessionFull       = errors.New("session is full")
+	ErrSessionNotActive  = errors.New("session is no longer looking for players")
+	ErrInvalidStatus     = errors.New("status must be in_progress, closed or cancelled")
+	ErrInvalidTransition = errors.New("session can't move to this status")
+	ErrMissingFields     = errors.New("game and objective can't be empty")
+	ErrRosterTooLarge    = errors.New("max players can't be below the accepted players")
+	ErrRankRequired      = errors.New("ranked sessions need a rank")
+	ErrSessionOver       = errors.New("session is over and can't be changed")
+	ErrInvalidTimeZone   = errors.New("time zone must be an IANA name like America/Sao_Paulo")
+	ErrInvalidStartsAt   = errors.New("starts_at must be a date and time like 2024-06-01T21:00")
+	ErrStartsInPast      = errors.New("session can't start in the past")
+	ErrStartsTooFar      = errors.New("session can't start more than 30 days ahead")
+	ErrInvalidTimeWindow = errors.New("time window must be a positive duration like 2h")
+)
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"log"
+	"time"
+
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// DefaultSessionTTL is how long a session stays listed when nobody closes it.
+const DefaultSessionTTL = 6 * time.Hour
+
+type ExpireSessionsUseCase struct {
+	sr    repository.SessionRepositoryInterface
+	lobby Lobby
+	ttl   time.Duration
+}
+
+func NewExpireSessionsUseCase(sr repository.SessionRepositoryInterface, l Lobby, ttl time.Duration) *ExpireSessionsUseCase {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+
+	return &ExpireSessionsUseCase{
+		sr:    sr,
+		lobby: l,
+		ttl:   ttl,
+	}
+}
+
+// Execute expires the sessions that started more than the TTL before now,
+// counting unscheduled ones from when they were posted, and archives their
+// lobby chats. It returns how many sessions expired.
+func (uc *ExpireSessionsUseCase) Execute(now time.Time) (int, error) {
+	ids, err := uc.sr.ExpireStale(now.Add(-uc.ttl), now)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := uc.lobby.Archive(id); err != nil {
+			log.Printf("Erro ao arquivar o chat da sessão %s: %v", id, err)
+		}
+	}
+
+	return len(ids), nil
+}
+
+// Run expires stale sessions every interval until stop is closed.
+func (uc *ExpireSessionsUseCase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			count, err := uc.Execute(now)
+
+			if err != nil {
+				log.Printf("Erro ao expirar sessões: %v", err)
+				continue
+			}
+
+			if count > 0 {
+				log.Printf("%d sessões expiradas", count)
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package session_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mauFade/playzy/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpireSessions(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	lobby := new(MockLobby)
+	now := time.Now()
+
+	sr.On("ExpireStale", now.Add(-2*time.Hour), now).Return([]string{"a", "b"}, nil).Once()
+	lobby.On("Archive", "a").Return(nil).Once()
+	// A session without a lobby doesn't stop the others
+	lobby.On("Archive", "b").Return(errors.New("session has no lobby chat")).Once()
+
+	uc := session.NewExpireSessionsUseCase(sr, lobby, 2*time.Hour)
+
+	count, err := uc.Execute(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	lobby.AssertExpectations(t)
+}
+
+func TestExpireSessionsDefaultTTL(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	now := time.Now()
+
+	sr.On("ExpireStale", now.Add(-session.DefaultSessionTTL), now).Return([]string{}, nil).Once()
+
+	count, err := session.NewExpireSessionsUseCase(sr, new(MockLobby), 0).Execute(now)
+
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	sr.AssertExpectations(t)
+}
@@ -4,17 +4,26 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
 	"github.com/mauFade/playzy/internal/repository"
 )
 
 type ListAvailableSessionsUseCase struct {
-	sr repository.SessionRepositoryInterface
+	sr  repository.SessionRepositoryInterface
+	smr repository.SessionMemberRepositoryInterface
 }
 
 type ListAvailableSessionsRequest struct {
-	Page int
-	Game string
-	Rank string
+	UserID      string
+	Page        int
+	Game        string
+	Rank        string
+	IncludeFull bool
+	// StartsAfter and StartsWithin narrow the list to sessions starting in a
+	// window; StartsWithin counts from StartsAfter, or from now
+	StartsAfter  *time.Time
+	StartsWithin time.Duration
 }
 
 type UserData struct {
@@ -34,6 +43,17 @@ type AvailableSessionsResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	CreatedAt time.Time `json:"created_at"`
 	User      UserData  `json:"user"`
+	// MaxPlayers counts the owner; OpenSlots is how many more can be accepted
+	MaxPlayers int    `json:"max_players"`
+	OpenSlots  int    `json:"open_slots"`
+	Status     string `json:"status"`
+	// StartsAt is in UTC; TimeZone is the owner's, for display
+	StartsAt *time.Time `json:"starts_at"`
+	TimeZone string     `json:"time_zone"`
+	// Members is the roster of accepted players
+	Members []model.SessionMember `json:"members"`
+	// Requests are the pending join requests, only shown to the owner
+	Requests []model.SessionMember `json:"requests,omitempty"`
 }
 
 type SessionsPageResponse struct {
@@ -42,14 +62,51 @@ type SessionsPageResponse struct {
 	Sessions   []AvailableSessionsResponse `json:"sessions"`
 }
 
-func NewListAvailableSessionsUseCase(s repository.SessionRepositoryInterface) *ListAvailableSessionsUseCase {
+func NewListAvailableSessionsUseCase(s repository.SessionRepositoryInterface, sm repository.SessionMemberRepositoryInterface) *ListAvailableSessionsUseCase {
 	return &ListAvailableSessionsUseCase{
-		sr: s,
+		sr:  s,
+		smr: sm,
 	}
 }
 
 func (u *ListAvailableSessionsUseCase) Execute(data *ListAvailableSessionsRequest) (*SessionsPageResponse, error) {
-	sessions, err := u.sr.FindAvailable(data.Page, data.Rank, data.Game)
+	if data.StartsWithin < 0 {
+		return nil, ErrInvalidTimeWindow
+	}
+
+	filter := dto.SessionFilter{
+		Page:        data.Page,
+		Rank:        data.Rank,
+		Game:        data.Game,
+		IncludeFull: data.IncludeFull,
+		StartsFrom:  data.StartsAfter,
+	}
+
+	if data.StartsWithin > 0 {
+		// Without a start the window opens now, so sessions that already
+		// started are left out
+		from := time.Now()
+		if data.StartsAfter != nil {
+			from = *data.StartsAfter
+		}
+
+		until := from.Add(data.StartsWithin)
+		filter.StartsFrom = &from
+		filter.StartsUntil = &until
+	}
+
+	sessions, err := u.sr.FindAvailable(filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(sessions.Sessions))
+	for i, s := range sessions.Sessions {
+		ids[i] = s.ID.String()
+	}
+
+	rosters, err := u.smr.ListBySessions(ids)
 
 	if err != nil {
 		return nil, err
@@ -58,14 +115,21 @@ func (u *ListAvailableSessionsUseCase) Execute(data *ListAvailableSessionsReques
 	resSessions := []AvailableSessionsResponse{}
 
 	for _, s := range sessions.Sessions {
+		roster := model.SessionModel{MaxPlayers: s.MaxPlayers, Players: s.Players}
+
 		ses := AvailableSessionsResponse{
-			ID:        s.ID,
-			Game:      s.Game,
-			Objective: s.Objective,
-			Rank:      s.Rank,
-			IsRanked:  s.IsRanked,
-			UpdatedAt: s.UpdatedAt,
-			CreatedAt: s.CreatedAt,
+			ID:         s.ID,
+			Game:       s.Game,
+			Objective:  s.Objective,
+			Rank:       s.Rank,
+			IsRanked:   s.IsRanked,
+			UpdatedAt:  s.UpdatedAt,
+			CreatedAt:  s.CreatedAt,
+			MaxPlayers: s.MaxPlayers,
+			OpenSlots:  roster.OpenSlots(),
+			Status:     s.Status,
+			StartsAt:   s.StartsAt,
+			TimeZone:   s.TimeZone,
 			User: UserData{
 				ID:       s.UserID,
 				Name:     s.UserName,
@@ -73,6 +137,18 @@ func (u *ListAvailableSessionsUseCase) Execute(data *ListAvailableSessionsReques
 				Gamertag: s.UserGamertag,
 				Avatar:   "https://i.pinimg.com/736x/6e/27/e4/6e27e43f5e02954d08e0bd3be06f7242.jpg",
 			},
+			Members: []model.SessionMember{},
+		}
+
+		isOwner := s.UserID.String() == data.UserID
+
+		for _, member := range rosters[s.ID.String()] {
+			switch {
+			case member.IsAccepted():
+				ses.Members = append(ses.Members, member)
+			case member.IsPending() && isOwner:
+				ses.Requests = append(ses.Requests, member)
+			}
 		}
 
 		resSessions = append(resSessions, ses)
@@ -0,0 +1,12 @@
+package session
+
+import "github.com/mauFade/playzy/internal/model"
+
+// Lobby manages the group chat that comes with every session. Chat failures
+// never fail the session action that triggered them.
+type Lobby interface {
+	Open(s *model.SessionModel) (*model.Conversation, error)
+	Join(sessionID, userID string) error
+	Leave(sessionID, userID string) error
+	Archive(sessionID string) error
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+type RemoveSessionMemberUseCase struct {
+	sr    repository.SessionRepositoryInterface
+	smr   repository.SessionMemberRepositoryInterface
+	lobby Lobby
+}
+
+// RemoveSessionMemberRequest removes MemberID from the session. When it is
+// the user themself they leave; otherwise the user must own the session and
+// the member is kicked.
+type RemoveSessionMemberRequest struct {
+	UserID    string
+	SessionID string
+	MemberID  string
+}
+
+func NewRemoveSessionMemberUseCase(sr repository.SessionRepositoryInterface, smr repository.SessionMemberRepositoryInterface, l Lobby) *RemoveSessionMemberUseCase {
+	return &RemoveSessionMemberUseCase{
+		sr:    sr,
+		smr:   smr,
+		lobby: l,
+	}
+}
+
+// Execute takes a player out of the session and its lobby chat. Leaving also
+// withdraws a pending request; a kicked player can't ask to join again.
+func (uc *RemoveSessionMemberUseCase) Execute(data *RemoveSessionMemberRequest) error {
+	leaving := data.MemberID == data.UserID
+
+	var s *model.SessionModel
+	var err error
+
+	if leaving {
+		s, err = findSession(uc.sr, data.SessionID)
+	} else {
+		s, err = findOwnSession(uc.sr, data.UserID, data.SessionID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := uuid.Parse(data.MemberID); err != nil {
+		return ErrInvalidUserID
+	}
+
+	if s.GetUserID().String() == data.MemberID {
+		return ErrOwnerCannotLeave
+	}
+
+	sessionID := s.GetID().String()
+
+	member, err := uc.smr.FindOne(sessionID, data.MemberID)
+
+	if err != nil {
+		return err
+	}
+
+	if member == nil || member.IsBlocked() {
+		return ErrMemberNotFound
+	}
+
+	var removed bool
+
+	if leaving {
+		removed, err = uc.smr.Remove(sessionID, data.MemberID)
+	} else {
+		removed, err = uc.smr.SetStatus(sessionID, data.MemberID, []model.SessionMemberStatus{model.MemberPending, model.MemberAccepted}, model.MemberKicked, time.Now())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return ErrMemberNotFound
+	}
+
+	if member.IsAccepted() {
+		if err := uc.lobby.Leave(sessionID, data.MemberID); err != nil {
+			log.Printf("Erro ao remover jogador %s do chat da sessão %s: %v", data.MemberID, sessionID, err)
+		}
+	}
+
+	return nil
+}
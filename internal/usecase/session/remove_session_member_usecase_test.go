@@ -0,0 +1,70 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRemoveSessionMemberKick(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+	player := uuid.NewString()
+
+	smr.On("FindOne", s.ID.String(), player).Return(&model.SessionMember{Status: model.MemberAccepted}, nil).Once()
+	smr.On("SetStatus", s.ID.String(), player, mock.Anything, model.MemberKicked, mock.Anything).Return(true, nil).Once()
+	lobby.On("Leave", s.ID.String(), player).Return(nil).Once()
+
+	uc := session.NewRemoveSessionMemberUseCase(sr, smr, lobby)
+
+	err := uc.Execute(&session.RemoveSessionMemberRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), MemberID: player})
+
+	assert.NoError(t, err)
+	smr.AssertExpectations(t)
+	lobby.AssertExpectations(t)
+}
+
+func TestRemoveSessionMemberKickOwnerOnly(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	s := postedSession(sr)
+
+	uc := session.NewRemoveSessionMemberUseCase(sr, smr, new(MockLobby))
+
+	err := uc.Execute(&session.RemoveSessionMemberRequest{UserID: uuid.NewString(), SessionID: s.ID.String(), MemberID: uuid.NewString()})
+
+	assert.ErrorIs(t, err, session.ErrNotSessionOwner)
+}
+
+func TestRemoveSessionMemberLeave(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+	pending := uuid.NewString()
+
+	smr.On("FindOne", s.ID.String(), pending).Return(&model.SessionMember{Status: model.MemberPending}, nil).Once()
+	smr.On("Remove", s.ID.String(), pending).Return(true, nil).Once()
+
+	uc := session.NewRemoveSessionMemberUseCase(sr, smr, lobby)
+
+	// Withdrawing a request never touches the lobby chat
+	err := uc.Execute(&session.RemoveSessionMemberRequest{UserID: pending, SessionID: s.ID.String(), MemberID: pending})
+	assert.NoError(t, err)
+	lobby.AssertNotCalled(t, "Leave", mock.Anything, mock.Anything)
+
+	err = uc.Execute(&session.RemoveSessionMemberRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), MemberID: s.UserID.String()})
+	assert.ErrorIs(t, err, session.ErrOwnerCannotLeave)
+
+	stranger := uuid.NewString()
+	smr.On("FindOne", s.ID.String(), stranger).Return((*model.SessionMember)(nil), nil).Once()
+
+	err = uc.Execute(&session.RemoveSessionMemberRequest{UserID: stranger, SessionID: s.ID.String(), MemberID: stranger})
+	assert.ErrorIs(t, err, session.ErrMemberNotFound)
+}
@@ -0,0 +1,78 @@
+package session
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+type RequestJoinSessionUseCase struct {
+	sr  repository.SessionRepositoryInterface
+	smr repository.SessionMemberRepositoryInterface
+}
+
+type RequestJoinSessionRequest struct {
+	UserID    string
+	SessionID string
+}
+
+func NewRequestJoinSessionUseCase(sr repository.SessionRepositoryInterface, smr repository.SessionMemberRepositoryInterface) *RequestJoinSessionUseCase {
+	return &RequestJoinSessionUseCase{
+		sr:  sr,
+		smr: smr,
+	}
+}
+
+// Execute asks the session owner to let the user in while there are open
+// slots. Players the owner declined or kicked can't ask again.
+func (uc *RequestJoinSessionUseCase) Execute(data *RequestJoinSessionRequest) (*model.SessionMember, error) {
+	s, err := findSession(uc.sr, data.SessionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(data.UserID)
+
+	if err != nil {
+		return nil, ErrInvalidUserID
+	}
+
+	if s.GetUserID() == userID {
+		return nil, ErrOwnerCannotJoin
+	}
+
+	if !s.IsActive() {
+		return nil, ErrSessionNotActive
+	}
+
+	if s.IsFull() {
+		return nil, ErrSessionFull
+	}
+
+	member := model.NewSessionMember(s.GetID(), userID, time.Now())
+
+	created, err := uc.smr.Request(member)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		return member, nil
+	}
+
+	existing, err := uc.smr.FindOne(s.GetID().String(), data.UserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil && existing.IsBlocked() {
+		return nil, ErrJoinRefused
+	}
+
+	return nil, ErrAlreadyRequested
+}
@@ -0,0 +1,135 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSessionMemberRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionMemberRepository) Request(member *model.SessionMember) (bool, error) {
+	args := m.Called(member)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionMemberRepository) FindOne(sessionID, userID string) (*model.SessionMember, error) {
+	args := m.Called(sessionID, userID)
+	return args.Get(0).(*model.SessionMember), args.Error(1)
+}
+
+func (m *MockSessionMemberRepository) Accept(sessionID, userID string, at time.Time) (bool, error) {
+	args := m.Called(sessionID, userID, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionMemberRepository) SetStatus(sessionID, userID string, from []model.SessionMemberStatus, to model.SessionMemberStatus, at time.Time) (bool, error) {
+	args := m.Called(sessionID, userID, from, to, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionMemberRepository) Remove(sessionID, userID string) (bool, error) {
+	args := m.Called(sessionID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionMemberRepository) ListBySessions(sessionIDs []string) (map[string][]model.SessionMember, error) {
+	args := m.Called(sessionIDs)
+	return args.Get(0).(map[string][]model.SessionMember), args.Error(1)
+}
+
+func postedSession(sr *MockCreateSessionRepository) *model.SessionModel {
+	s := model.NewSessionModel(uuid.New(), uuid.New(), "Valorant", "Climb", nil, false, time.Now(), time.Now())
+	s.SetMaxPlayers(5)
+	sr.On("FindByID", s.ID).Return(s, nil)
+	return s
+}
+
+func TestRequestJoinSession(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	s := postedSession(sr)
+	player := uuid.New()
+
+	smr.On("Request", mock.MatchedBy(func(m *model.SessionMember) bool {
+		return m.SessionID == s.ID && m.UserID == player && m.IsPending()
+	})).Return(true, nil).Once()
+
+	uc := session.NewRequestJoinSessionUseCase(sr, smr)
+
+	member, err := uc.Execute(&session.RequestJoinSessionRequest{UserID: player.String(), SessionID: s.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.MemberPending, member.Status)
+	smr.AssertExpectations(t)
+}
+
+func TestRequestJoinSessionRejectsOwnerAndRepeats(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	s := postedSession(sr)
+	uc := session.NewRequestJoinSessionUseCase(sr, smr)
+
+	_, err := uc.Execute(&session.RequestJoinSessionRequest{UserID: s.UserID.String(), SessionID: s.ID.String()})
+	assert.ErrorIs(t, err, session.ErrOwnerCannotJoin)
+
+	pending, kicked := uuid.New(), uuid.New()
+	smr.On("Request", mock.Anything).Return(false, nil)
+	smr.On("FindOne", s.ID.String(), pending.String()).Return(&model.SessionMember{Status: model.MemberPending}, nil).Once()
+	smr.On("FindOne", s.ID.String(), kicked.String()).Return(&model.SessionMember{Status: model.MemberKicked}, nil).Once()
+
+	_, err = uc.Execute(&session.RequestJoinSessionRequest{UserID: pending.String(), SessionID: s.ID.String()})
+	assert.ErrorIs(t, err, session.ErrAlreadyRequested)
+
+	_, err = uc.Execute(&session.RequestJoinSessionRequest{UserID: kicked.String(), SessionID: s.ID.String()})
+	assert.ErrorIs(t, err, session.ErrJoinRefused)
+}
+
+func TestRequestJoinSessionNotFound(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	uc := session.NewRequestJoinSessionUseCase(sr, smr)
+
+	missing := uuid.New()
+	sr.On("FindByID", missing).Return((*model.SessionModel)(nil), nil).Once()
+
+	_, err := uc.Execute(&session.RequestJoinSessionRequest{UserID: uuid.NewString(), SessionID: missing.String()})
+	assert.ErrorIs(t, err, session.ErrSessionNotFound)
+
+	_, err = uc.Execute(&session.RequestJoinSessionRequest{UserID: uuid.NewString(), SessionID: "nope"})
+	assert.ErrorIs(t, err, session.ErrInvalidSessionID)
+}
+
+func TestRequestJoinSessionFull(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	s := postedSession(sr)
+	s.Players = 4
+
+	uc := session.NewRequestJoinSessionUseCase(sr, smr)
+
+	_, err := uc.Execute(&session.RequestJoinSessionRequest{UserID: uuid.NewString(), SessionID: s.ID.String()})
+
+	assert.ErrorIs(t, err, session.ErrSessionFull)
+	smr.AssertNotCalled(t, "Request", mock.Anything)
+}
+
+func TestRequestJoinSessionNotActive(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	s := postedSession(sr)
+	s.Status = model.SessionInProgress
+
+	uc := session.NewRequestJoinSessionUseCase(sr, smr)
+
+	_, err := uc.Execute(&session.RequestJoinSessionRequest{UserID: uuid.NewString(), SessionID: s.ID.String()})
+
+	assert.ErrorIs(t, err, session.ErrSessionNotActive)
+}
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+type RespondJoinRequestUseCase struct {
+	sr    repository.SessionRepositoryInterface
+	smr   repository.SessionMemberRepositoryInterface
+	lobby Lobby
+}
+
+type RespondJoinRequestRequest struct {
+	UserID    string
+	SessionID string
+	MemberID  string
+	Accept    bool
+}
+
+func NewRespondJoinRequestUseCase(sr repository.SessionRepositoryInterface, smr repository.SessionMemberRepositoryInterface, l Lobby) *RespondJoinRequestUseCase {
+	return &RespondJoinRequestUseCase{
+		sr:    sr,
+		smr:   smr,
+		lobby: l,
+	}
+}
+
+// Execute lets the owner accept or decline a pending join request. Accepted
+// players are added to the lobby chat; accepting into a full session fails.
+func (uc *RespondJoinRequestUseCase) Execute(data *RespondJoinRequestRequest) (*model.SessionMember, error) {
+	s, err := findOwnSession(uc.sr, data.UserID, data.SessionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(data.MemberID); err != nil {
+		return nil, ErrInvalidUserID
+	}
+
+	if data.Accept && !s.IsActive() {
+		return nil, ErrSessionNotActive
+	}
+
+	sessionID := s.GetID().String()
+
+	member, err := uc.smr.FindOne(sessionID, data.MemberID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+
+	status := model.MemberDeclined
+	if data.Accept {
+		status = model.MemberAccepted
+	}
+
+	respondedAt := time.Now()
+
+	var changed bool
+
+	if data.Accept {
+		// The repository checks capacity while holding the session
+		changed, err = uc.smr.Accept(sessionID, data.MemberID, respondedAt)
+	} else {
+		changed, err = uc.smr.SetStatus(sessionID, data.MemberID, []model.SessionMemberStatus{model.MemberPending}, status, respondedAt)
+	}
+
+	if errors.Is(err, repository.ErrNoOpenSlots) {
+		return nil, ErrSessionFull
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		return nil, ErrRequestNotPending
+	}
+
+	member.Status = status
+	member.RespondedAt = &respondedAt
+
+	if data.Accept {
+		if err := uc.lobby.Join(sessionID, data.MemberID); err != nil {
+			log.Printf("Erro ao adicionar jogador %s ao chat da sessão %s: %v", data.MemberID, sessionID, err)
+		}
+	}
+
+	return member, nil
+}
@@ -0,0 +1,92 @@
+package session_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRespondJoinRequestAcceptJoinsLobby(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+	player := uuid.NewString()
+
+	smr.On("FindOne", s.ID.String(), player).Return(&model.SessionMember{Status: model.MemberPending}, nil).Once()
+	smr.On("Accept", s.ID.String(), player, mock.Anything).Return(true, nil).Once()
+	// Chat failures don't undo the acceptance
+	lobby.On("Join", s.ID.String(), player).Return(errors.New("db down")).Once()
+
+	uc := session.NewRespondJoinRequestUseCase(sr, smr, lobby)
+
+	member, err := uc.Execute(&session.RespondJoinRequestRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), MemberID: player, Accept: true})
+
+	assert.NoError(t, err)
+	assert.True(t, member.IsAccepted())
+	assert.NotNil(t, member.RespondedAt)
+	lobby.AssertExpectations(t)
+}
+
+func TestRespondJoinRequestDecline(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+	player := uuid.NewString()
+
+	smr.On("FindOne", s.ID.String(), player).Return(&model.SessionMember{Status: model.MemberPending}, nil)
+	smr.On("SetStatus", s.ID.String(), player, mock.Anything, model.MemberDeclined, mock.Anything).Return(true, nil).Once()
+	smr.On("SetStatus", s.ID.String(), player, mock.Anything, model.MemberDeclined, mock.Anything).Return(false, nil).Once()
+
+	uc := session.NewRespondJoinRequestUseCase(sr, smr, lobby)
+	req := &session.RespondJoinRequestRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), MemberID: player}
+
+	member, err := uc.Execute(req)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MemberDeclined, member.Status)
+
+	// Answered from another device meanwhile
+	_, err = uc.Execute(req)
+	assert.ErrorIs(t, err, session.ErrRequestNotPending)
+	lobby.AssertNotCalled(t, "Join", mock.Anything, mock.Anything)
+}
+
+func TestRespondJoinRequestOwnerOnly(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	s := postedSession(sr)
+	player := uuid.NewString()
+
+	uc := session.NewRespondJoinRequestUseCase(sr, smr, new(MockLobby))
+
+	_, err := uc.Execute(&session.RespondJoinRequestRequest{UserID: player, SessionID: s.ID.String(), MemberID: player, Accept: true})
+
+	assert.ErrorIs(t, err, session.ErrNotSessionOwner)
+	smr.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRespondJoinRequestAcceptFullSession(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+	player := uuid.NewString()
+
+	smr.On("FindOne", s.ID.String(), player).Return(&model.SessionMember{Status: model.MemberPending}, nil).Once()
+	// Another request took the last slot first
+	smr.On("Accept", s.ID.String(), player, mock.Anything).Return(false, repository.ErrNoOpenSlots).Once()
+
+	uc := session.NewRespondJoinRequestUseCase(sr, smr, lobby)
+
+	_, err := uc.Execute(&session.RespondJoinRequestRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), MemberID: player, Accept: true})
+
+	assert.ErrorIs(t, err, session.ErrSessionFull)
+	lobby.AssertNotCalled(t, "Join", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"time"
+	// Zone names must resolve even on images without system tzdata
+	_ "time/tzdata"
+)
+
+const (
+	// maxScheduleAhead is how far ahead a session can be planned
+	maxScheduleAhead = 30 * 24 * time.Hour
+	// startGrace tolerates clocks a little behind ours
+	startGrace = 5 * time.Minute
+)
+
+// localLayouts are accepted for start times without an offset, which are
+// read in the owner's time zone.
+var localLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// resolveSchedule turns the start time and IANA zone sent by the owner into a
+// UTC start time. An empty startsAt means the session starts right away.
+func resolveSchedule(startsAt, timeZone string, now time.Time) (*time.Time, string, error) {
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+
+	// "Local" would be the server's zone, not the owner's
+	if timeZone == "Local" {
+		return nil, "", ErrInvalidTimeZone
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+
+	if err != nil {
+		return nil, "", ErrInvalidTimeZone
+	}
+
+	if startsAt == "" {
+		return nil, timeZone, nil
+	}
+
+	start, err := parseStartsAt(startsAt, loc)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if start.Before(now.Add(-startGrace)) {
+		return nil, "", ErrStartsInPast
+	}
+
+	if start.After(now.Add(maxScheduleAhead)) {
+		return nil, "", ErrStartsTooFar
+	}
+
+	start = start.UTC()
+
+	return &start, timeZone, nil
+}
+
+func parseStartsAt(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, ErrInvalidStartsAt
+}
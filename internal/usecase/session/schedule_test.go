@@ -0,0 +1,121 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateScheduledSessionInOwnerTimeZone(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	ur := new(MockSessionUserRepository)
+	lobby := new(MockLobby)
+	userID := uuid.New()
+
+	ur.On("FindByID", userID.String()).Return(&model.UserModel{ID: userID}, nil).Once()
+	sr.On("Create", mock.Anything).Return(nil).Once()
+	lobby.On("Open", mock.Anything).Return(&model.Conversation{ID: uuid.New(), Kind: model.ConversationGroup}, nil).Once()
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	tomorrow := time.Now().In(loc).AddDate(0, 0, 1)
+	raid := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 21, 0, 0, 0, loc)
+
+	uc := session.NewCreateSessionUseCase(sr, ur, lobby)
+
+	res, err := uc.Execute(&session.CreateSessionRequest{
+		UserID:    userID.String(),
+		Game:      "Destiny 2",
+		Objective: "Raid",
+		StartsAt:  raid.Format("2006-01-02T15:04"),
+		TimeZone:  "America/Sao_Paulo",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "America/Sao_Paulo", res.GetTimeZone())
+	assert.Equal(t, time.UTC, res.GetStartsAt().Location())
+	assert.True(t, raid.Equal(*res.GetStartsAt()))
+	assert.True(t, raid.Equal(res.StartTime()))
+}
+
+func TestCreateSessionRejectsBadSchedule(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	ur := new(MockSessionUserRepository)
+	uc := session.NewCreateSessionUseCase(sr, ur, new(MockLobby))
+
+	cases := map[string]struct {
+		startsAt string
+		timeZone string
+		err      error
+	}{
+		"past":         {time.Now().Add(-time.Hour).Format(time.RFC3339), "", session.ErrStartsInPast},
+		"too far":      {time.Now().AddDate(0, 2, 0).Format(time.RFC3339), "", session.ErrStartsTooFar},
+		"unknown zone": {"", "Mars/Olympus_Mons", session.ErrInvalidTimeZone},
+		"server zone":  {"", "Local", session.ErrInvalidTimeZone},
+		"bad format":   {"tonight at nine", "Europe/Lisbon", session.ErrInvalidStartsAt},
+	}
+
+	for name, c := range cases {
+		_, err := uc.Execute(&session.CreateSessionRequest{UserID: uuid.NewString(), Game: "Game", Objective: "Obj", StartsAt: c.startsAt, TimeZone: c.timeZone})
+		assert.ErrorIs(t, err, c.err, name)
+	}
+
+	ur.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+func TestUpdateSessionTimeZoneKeepsStart(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+	start := time.Now().Add(3 * time.Hour).UTC()
+	s.SetSchedule(&start, "UTC")
+
+	sr.On("Update", s).Return(s, nil).Once()
+
+	zone := "Asia/Tokyo"
+	res, err := session.NewUpdateSessionUseCase(sr).Execute(&session.UpdateSessionRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), TimeZone: &zone})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", res.GetTimeZone())
+	assert.True(t, start.Equal(*res.GetStartsAt()))
+}
+
+func TestListSessionsStartingWithin(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+
+	sr.On("FindAvailable", mock.MatchedBy(func(f dto.SessionFilter) bool {
+		return f.StartsFrom != nil && f.StartsUntil != nil &&
+			time.Since(*f.StartsFrom) < time.Minute && f.StartsUntil.Sub(*f.StartsFrom) == 2*time.Hour
+	})).Return(&dto.SessionsPageResponse{Page: 1, Sessions: []dto.SessionWithUser{}}, nil).Once()
+	smr.On("ListBySessions", []string{}).Return(map[string][]model.SessionMember{}, nil).Once()
+
+	uc := session.NewListAvailableSessionsUseCase(sr, smr)
+
+	_, err := uc.Execute(&session.ListAvailableSessionsRequest{Page: 1, StartsWithin: 2 * time.Hour})
+	assert.NoError(t, err)
+	sr.AssertExpectations(t)
+
+	_, err = uc.Execute(&session.ListAvailableSessionsRequest{Page: 1, StartsWithin: -time.Hour})
+	assert.ErrorIs(t, err, session.ErrInvalidTimeWindow)
+}
+
+func TestListSessionsStartingWithinAfter(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	smr := new(MockSessionMemberRepository)
+
+	after := time.Now().Add(24 * time.Hour).UTC()
+	sr.On("FindAvailable", mock.MatchedBy(func(f dto.SessionFilter) bool {
+		return f.StartsFrom != nil && f.StartsFrom.Equal(after) && f.StartsUntil != nil && f.StartsUntil.Equal(after.Add(time.Hour))
+	})).Return(&dto.SessionsPageResponse{Page: 1, Sessions: []dto.SessionWithUser{}}, nil).Once()
+	smr.On("ListBySessions", []string{}).Return(map[string][]model.SessionMember{}, nil).Once()
+
+	_, err := session.NewListAvailableSessionsUseCase(sr, smr).Execute(&session.ListAvailableSessionsRequest{Page: 1, StartsAfter: &after, StartsWithin: time.Hour})
+
+	assert.NoError(t, err)
+	sr.AssertExpectations(t)
+}
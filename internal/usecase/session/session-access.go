@@ -0,0 +1,43 @@
+package session
+
+import (
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// findSession loads a session by its path ID.
+func findSession(sr repository.SessionRepositoryInterface, sessionID string) (*model.SessionModel, error) {
+	id, err := uuid.Parse(sessionID)
+
+	if err != nil {
+		return nil, ErrInvalidSessionID
+	}
+
+	s, err := sr.FindByID(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	return s, nil
+}
+
+// findOwnSession loads a session the user posted.
+func findOwnSession(sr repository.SessionRepositoryInterface, userID, sessionID string) (*model.SessionModel, error) {
+	s, err := findSession(sr, sessionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s.GetUserID().String() != userID {
+		return nil, ErrNotSessionOwner
+	}
+
+	return s, nil
+}
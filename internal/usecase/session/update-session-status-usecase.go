@@ -0,0 +1,74 @@
+package session
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// ownerStatuses are the statuses an owner can pick; open, full and expired
+// are only set by the server.
+var ownerStatuses = []model.SessionStatus{model.SessionInProgress, model.SessionClosed, model.SessionCancelled}
+
+type UpdateSessionStatusUseCase struct {
+	sr    repository.SessionRepositoryInterface
+	lobby Lobby
+}
+
+type UpdateSessionStatusRequest struct {
+	UserID    string
+	SessionID string
+	Status    model.SessionStatus
+}
+
+func NewUpdateSessionStatusUseCase(sr repository.SessionRepositoryInterface, l Lobby) *UpdateSessionStatusUseCase {
+	return &UpdateSessionStatusUseCase{
+		sr:    sr,
+		lobby: l,
+	}
+}
+
+// Execute lets the owner start, close or cancel their session. Once it is
+// over the lobby chat turns read-only.
+func (uc *UpdateSessionStatusUseCase) Execute(data *UpdateSessionStatusRequest) (*model.SessionModel, error) {
+	if !slices.Contains(ownerStatuses, data.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	s, err := findOwnSession(uc.sr, data.UserID, data.SessionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Open and full only differ by the roster, so either can be left
+	from := []model.SessionStatus{s.GetStatus()}
+	if s.IsActive() {
+		from = []model.SessionStatus{model.SessionOpen, model.SessionFull}
+	}
+
+	if !s.TransitionTo(data.Status, time.Now()) {
+		return nil, ErrInvalidTransition
+	}
+
+	changed, err := uc.sr.UpdateStatus(s.GetID().String(), from, s.GetStatus(), s.GetUpdatedAt())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		return nil, ErrInvalidTransition
+	}
+
+	if s.GetStatus().IsFinal() {
+		if err := uc.lobby.Archive(s.GetID().String()); err != nil {
+			log.Printf("Erro ao arquivar o chat da sessão %s: %v", s.GetID(), err)
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+type UpdateSessionUseCase struct {
+	sr repository.SessionRepositoryInterface
+}
+
+// UpdateSessionRequest changes the fields that are set. An empty Rank clears
+// it, which a ranked session only allows along with IsRanked false, and an
+// empty StartsAt makes the session start right away.
+type UpdateSessionRequest struct {
+	UserID     string
+	SessionID  string
+	Game       *string
+	Objective  *string
+	Rank       *string
+	IsRanked   *bool
+	MaxPlayers *int
+	StartsAt   *string
+	TimeZone   *string
+}
+
+func NewUpdateSessionUseCase(sr repository.SessionRepositoryInterface) *UpdateSessionUseCase {
+	return &UpdateSessionUseCase{
+		sr: sr,
+	}
+}
+
+func (uc *UpdateSessionUseCase) Execute(data *UpdateSessionRequest) (*model.SessionModel, error) {
+	s, err := findOwnSession(uc.sr, data.UserID, data.SessionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s.GetStatus().IsFinal() {
+		return nil, ErrSessionOver
+	}
+
+	if data.Game != nil {
+		game := strings.TrimSpace(*data.Game)
+		if game == "" {
+			return nil, ErrMissingFields
+		}
+
+		s.SetGame(game)
+	}
+
+	if data.Objective != nil {
+		objective := strings.TrimSpace(*data.Objective)
+		if objective == "" {
+			return nil, ErrMissingFields
+		}
+
+		s.SetObjective(objective)
+	}
+
+	if data.Rank != nil {
+		s.SetRank(normalizeRank(data.Rank))
+	}
+
+	if data.IsRanked != nil {
+		s.IsRanked = *data.IsRanked
+	}
+
+	if s.GetIsRanked() && s.GetRank() == nil {
+		return nil, ErrRankRequired
+	}
+
+	if data.MaxPlayers != nil {
+		if err := checkMaxPlayers(*data.MaxPlayers); err != nil {
+			return nil, err
+		}
+
+		s.SetMaxPlayers(*data.MaxPlayers)
+	}
+
+	if data.StartsAt != nil || data.TimeZone != nil {
+		if err := uc.reschedule(s, data); err != nil {
+			return nil, err
+		}
+	}
+
+	s.UpdatedAt = time.Now()
+
+	updated, err := uc.sr.Update(s)
+
+	if err != nil {
+		// The roster is counted while the session is held, so accepts
+		// racing with the update are seen
+		if errors.Is(err, repository.ErrRosterTooLarge) {
+			return nil, ErrRosterTooLarge
+		}
+
+		return nil, err
+	}
+
+	if updated == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	return updated, nil
+}
+
+// reschedule applies a new start time or time zone. Changing only the zone
+// keeps the same moment and only changes how it is shown.
+func (uc *UpdateSessionUseCase) reschedule(s *model.SessionModel, data *UpdateSessionRequest) error {
+	timeZone := s.GetTimeZone()
+	if data.TimeZone != nil {
+		timeZone = *data.TimeZone
+	}
+
+	if data.StartsAt == nil {
+		_, zone, err := resolveSchedule("", timeZone, time.Now())
+
+		if err != nil {
+			return err
+		}
+
+		s.SetSchedule(s.GetStartsAt(), zone)
+
+		return nil
+	}
+
+	// Once the game is on there is nothing left to schedule
+	if !s.IsActive() {
+		return ErrSessionNotActive
+	}
+
+	startsAt, zone, err := resolveSchedule(*data.StartsAt, timeZone, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	s.SetSchedule(startsAt, zone)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateSessionStatusStart(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+
+	sr.On("UpdateStatus", s.ID.String(), []model.SessionStatus{model.SessionOpen, model.SessionFull}, model.SessionInProgress, mock.Anything).Return(true, nil).Once()
+
+	uc := session.NewUpdateSessionStatusUseCase(sr, lobby)
+
+	res, err := uc.Execute(&session.UpdateSessionStatusRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), Status: model.SessionInProgress})
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.SessionInProgress, res.GetStatus())
+	// The lobby stays open while the game is on
+	lobby.AssertNotCalled(t, "Archive", mock.Anything)
+}
+
+func TestUpdateSessionStatusCloseArchivesLobby(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+	s.Status = model.SessionInProgress
+
+	sr.On("UpdateStatus", s.ID.String(), []model.SessionStatus{model.SessionInProgress}, model.SessionClosed, mock.Anything).Return(true, nil).Once()
+	lobby.On("Archive", s.ID.String()).Return(nil).Once()
+
+	uc := session.NewUpdateSessionStatusUseCase(sr, lobby)
+
+	_, err := uc.Execute(&session.UpdateSessionStatusRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), Status: model.SessionClosed})
+
+	assert.NoError(t, err)
+	lobby.AssertExpectations(t)
+}
+
+func TestUpdateSessionStatusRejects(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+	uc := session.NewUpdateSessionStatusUseCase(sr, new(MockLobby))
+
+	_, err := uc.Execute(&session.UpdateSessionStatusRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), Status: model.SessionExpired})
+	assert.ErrorIs(t, err, session.ErrInvalidStatus)
+
+	_, err = uc.Execute(&session.UpdateSessionStatusRequest{UserID: uuid.NewString(), SessionID: s.ID.String(), Status: model.SessionClosed})
+	assert.ErrorIs(t, err, session.ErrNotSessionOwner)
+
+	s.Status = model.SessionCancelled
+	_, err = uc.Execute(&session.UpdateSessionStatusRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), Status: model.SessionInProgress})
+	assert.ErrorIs(t, err, session.ErrInvalidTransition)
+
+	sr.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateSessionStatusLostRace(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	lobby := new(MockLobby)
+	s := postedSession(sr)
+
+	// Expired by the worker between the read and the write
+	sr.On("UpdateStatus", s.ID.String(), mock.Anything, model.SessionCancelled, mock.Anything).Return(false, nil).Once()
+
+	uc := session.NewUpdateSessionStatusUseCase(sr, lobby)
+
+	_, err := uc.Execute(&session.UpdateSessionStatusRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), Status: model.SessionCancelled})
+
+	assert.ErrorIs(t, err, session.ErrInvalidTransition)
+	lobby.AssertNotCalled(t, "Archive", mock.Anything)
+}
@@ -0,0 +1,133 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/mauFade/playzy/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateSession(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+	rank := "Immortal"
+	s.SetRank(&rank)
+	s.IsRanked = true
+	before := s.GetUpdatedAt()
+
+	sr.On("Update", s).Return(s, nil).Once()
+
+	uc := session.NewUpdateSessionUseCase(sr)
+
+	objective := "  Chill games  "
+	noRank := ""
+	unranked := false
+	maxPlayers := 3
+
+	res, err := uc.Execute(&session.UpdateSessionRequest{
+		UserID:     s.UserID.String(),
+		SessionID:  s.ID.String(),
+		Objective:  &objective,
+		Rank:       &noRank,
+		IsRanked:   &unranked,
+		MaxPlayers: &maxPlayers,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Valorant", res.GetGame())
+	assert.Equal(t, "Chill games", res.GetObjective())
+	assert.Nil(t, res.GetRank())
+	assert.False(t, res.GetIsRanked())
+	assert.Equal(t, 3, res.GetMaxPlayers())
+	assert.True(t, res.GetUpdatedAt().After(before))
+}
+
+func TestUpdateSessionRankKeepsRanked(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+	s.IsRanked = true
+
+	sr.On("Update", s).Return(s, nil).Once()
+
+	rank := "Diamond"
+	res, err := session.NewUpdateSessionUseCase(sr).Execute(&session.UpdateSessionRequest{
+		UserID:    s.UserID.String(),
+		SessionID: s.ID.String(),
+		Rank:      &rank,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Diamond", *res.GetRank())
+	assert.True(t, res.GetIsRanked())
+}
+
+func TestUpdateSessionClearRankOfRankedSession(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+	rank := "Immortal"
+	s.SetRank(&rank)
+	s.IsRanked = true
+
+	noRank := ""
+	_, err := session.NewUpdateSessionUseCase(sr).Execute(&session.UpdateSessionRequest{
+		UserID:    s.UserID.String(),
+		SessionID: s.ID.String(),
+		Rank:      &noRank,
+	})
+
+	assert.ErrorIs(t, err, session.ErrRankRequired)
+	sr.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateSessionRosterTooLarge(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+
+	// The owner plus two players accepted meanwhile don't fit in a duo
+	sr.On("Update", s).Return((*model.SessionModel)(nil), repository.ErrRosterTooLarge).Once()
+
+	duo := 2
+	_, err := session.NewUpdateSessionUseCase(sr).Execute(&session.UpdateSessionRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), MaxPlayers: &duo})
+
+	assert.ErrorIs(t, err, session.ErrRosterTooLarge)
+	sr.AssertExpectations(t)
+}
+
+func TestUpdateSessionValidation(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+	uc := session.NewUpdateSessionUseCase(sr)
+
+	blank := " "
+	_, err := uc.Execute(&session.UpdateSessionRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), Game: &blank})
+	assert.ErrorIs(t, err, session.ErrMissingFields)
+
+	tooMany := 500
+	_, err = uc.Execute(&session.UpdateSessionRequest{UserID: s.UserID.String(), SessionID: s.ID.String(), MaxPlayers: &tooMany})
+	assert.ErrorIs(t, err, session.ErrInvalidMaxPlayers)
+
+	s.Status = model.SessionClosed
+	_, err = uc.Execute(&session.UpdateSessionRequest{UserID: s.UserID.String(), SessionID: s.ID.String()})
+	assert.ErrorIs(t, err, session.ErrSessionOver)
+
+	sr.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateSessionOwnership(t *testing.T) {
+	sr := new(MockCreateSessionRepository)
+	s := postedSession(sr)
+	uc := session.NewUpdateSessionUseCase(sr)
+
+	_, err := uc.Execute(&session.UpdateSessionRequest{UserID: uuid.NewString(), SessionID: s.ID.String()})
+	assert.ErrorIs(t, err, session.ErrNotSessionOwner)
+
+	missing := uuid.New()
+	sr.On("FindByID", missing).Return((*model.SessionModel)(nil), nil).Once()
+
+	_, err = uc.Execute(&session.UpdateSessionRequest{UserID: s.UserID.String(), SessionID: missing.String()})
+	assert.ErrorIs(t, err, session.ErrSessionNotFound)
+}
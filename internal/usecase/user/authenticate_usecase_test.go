@@ -45,6 +45,16 @@ func (m *MockAuthUserRepository) Create(user *model.UserModel) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthUserRepository) FindByIDs(ids []string) ([]*model.UserModel, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]*model.UserModel), args.Error(1)
+}
+
+func (m *MockAuthUserRepository) UpdateLastSeen(id string, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
 func TestAuthenticateUserUseCaseExecuteSuccess(t *testing.T) {
 	mockRepo := new(MockAuthUserRepository)
 	useCase := user.NewAuthenticateUserUseCase(mockRepo)
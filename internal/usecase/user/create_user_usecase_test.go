@@ -44,6 +44,16 @@ func (m *MockUserRepository) Create(user *model.UserModel) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) FindByIDs(ids []string) ([]*model.UserModel, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]*model.UserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateLastSeen(id string, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
 func TestCreateUserUseCaseExecuteSuccess(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	useCase := user.NewCreateUserUseCase(mockRepo)
@@ -0,0 +1,77 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// maxPresenceBatch caps how many users can be looked up in a single request.
+const maxPresenceBatch = 100
+
+// PresenceReader exposes the live status of connected users.
+type PresenceReader interface {
+	Presence(userID string) model.PresenceStatus
+}
+
+type GetUsersPresenceUseCase struct {
+	userRepository repository.UserRepositoryInterface
+	presence       PresenceReader
+}
+
+type GetUsersPresenceRequest struct {
+	UserIDs []string
+}
+
+func NewGetUsersPresenceUseCase(r repository.UserRepositoryInterface, p PresenceReader) *GetUsersPresenceUseCase {
+	return &GetUsersPresenceUseCase{
+		userRepository: r,
+		presence:       p,
+	}
+}
+
+func (uc *GetUsersPresenceUseCase) Execute(data *GetUsersPresenceRequest) ([]model.Presence, error) {
+	if len(data.UserIDs) == 0 {
+		return nil, errors.New("at least one user id is required")
+	}
+
+	if len(data.UserIDs) > maxPresenceBatch {
+		return nil, errors.New("too many user ids")
+	}
+
+	for _, id := range data.UserIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, errors.New("invalid user id: " + id)
+		}
+	}
+
+	users, err := uc.userRepository.FindByIDs(data.UserIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	presences := []model.Presence{}
+
+	for _, u := range users {
+		id := u.GetID().String()
+		status := uc.presence.Presence(id)
+		lastSeenAt := u.GetLastSeenAt()
+
+		if status != model.PresenceOffline {
+			now := time.Now()
+			lastSeenAt = &now
+		}
+
+		presences = append(presences, model.Presence{
+			UserID:     id,
+			Status:     status,
+			LastSeenAt: lastSeenAt,
+		})
+	}
+
+	return presences, nil
+}
@@ -0,0 +1,60 @@
+package user_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/usecase/user"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePresenceReader map[string]model.PresenceStatus
+
+func (f fakePresenceReader) Presence(userID string) model.PresenceStatus {
+	if status, ok := f[userID]; ok {
+		return status
+	}
+
+	return model.PresenceOffline
+}
+
+func TestGetUsersPresenceUseCaseExecuteSuccess(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+
+	onlineID := uuid.New()
+	offlineID := uuid.New()
+	lastSeen := time.Now().Add(-time.Hour)
+
+	ids := []string{onlineID.String(), offlineID.String()}
+	mockRepo.On("FindByIDs", ids).Return([]*model.UserModel{
+		{ID: onlineID},
+		{ID: offlineID, LastSeenAt: &lastSeen},
+	}, nil).Once()
+
+	useCase := user.NewGetUsersPresenceUseCase(mockRepo, fakePresenceReader{
+		onlineID.String(): model.PresenceOnline,
+	})
+
+	res, err := useCase.Execute(&user.GetUsersPresenceRequest{UserIDs: ids})
+
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, model.PresenceOnline, res[0].Status)
+	assert.WithinDuration(t, time.Now(), *res[0].LastSeenAt, time.Second)
+	assert.Equal(t, model.PresenceOffline, res[1].Status)
+	assert.Equal(t, lastSeen, *res[1].LastSeenAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetUsersPresenceUseCaseExecuteInvalidID(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	useCase := user.NewGetUsersPresenceUseCase(mockRepo, fakePresenceReader{})
+
+	res, err := useCase.Execute(&user.GetUsersPresenceRequest{UserIDs: []string{"not-an-id"}})
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+	mockRepo.AssertNotCalled(t, "FindByIDs")
+}
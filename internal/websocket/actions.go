@@ -0,0 +1,36 @@
+package websocket
+
+// MessageActions changes messages on behalf of connected users. The socket
+// only decodes the request and pushes the outcome; the rules about who may do
+// what live behind it. Failures caused by the request itself should be
+// returned as a *HandlerError so the client learns what went wrong.
+type MessageActions interface {
+	Edit(userID, messageID, content string) (*MessageUpdate, error)
+	Delete(userID, messageID string) (*MessageUpdate, error)
+	AddReaction(userID, messageID, emoji string) (*MessageUpdate, error)
+	RemoveReaction(userID, messageID, emoji string) (*MessageUpdate, error)
+}
+
+// MessageUpdate is the outcome of a MessageActions call.
+type MessageUpdate struct {
+	// Payload is pushed as is to every participant.
+	Payload any
+	// Participants are the users of the conversation the message belongs to.
+	Participants []string
+	// Changed is false when the message was already in the requested state,
+	// in which case nobody is notified.
+	Changed bool
+}
+
+// publishUpdate pushes a message update to its participants.
+func (m *Manager) publishUpdate(eventType string, update *MessageUpdate, failure string) error {
+	if !update.Changed {
+		return nil
+	}
+
+	if err := m.Notify(update.Participants, eventType, update.Payload); err != nil {
+		return NewHandlerError(ErrCodeServerBusy, failure)
+	}
+
+	return nil
+}
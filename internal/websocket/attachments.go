@@ -0,0 +1,41 @@
+package websocket
+
+import (
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+)
+
+// maxAttachmentsPerMessage caps how many files a single message may share.
+const maxAttachmentsPerMessage = 10
+
+// checkAttachments makes sure every file shared in the message was uploaded by
+// the sender and wasn't shared in another message yet.
+func (m *Manager) checkAttachments(c *Client, message model.Message) error {
+	if !message.HasAttachments() {
+		return nil
+	}
+
+	if m.attachments == nil {
+		return NewHandlerError(ErrCodeInvalidPayload, "attachments are not supported")
+	}
+
+	seen := make(map[string]bool, len(message.Attachments))
+
+	for _, id := range message.AttachmentIDs() {
+		if id == uuid.Nil.String() || seen[id] {
+			return NewHandlerError(ErrCodeInvalidPayload, "invalid attachment ID")
+		}
+		seen[id] = true
+
+		a, err := m.attachments.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		if a == nil || a.UploaderID != c.userID || a.IsSent() {
+			return NewHandlerError(ErrCodeNotFound, "attachment not found")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(a *model.Attachment) error {
+	args := m.Called(a)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) FindByID(id string) (*model.Attachment, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByMessages(messageIDs []string) (map[string][]model.Attachment, error) {
+	args := m.Called(messageIDs)
+	return args.Get(0).(map[string][]model.Attachment), args.Error(1)
+}
+
+func TestHandleMessageSendDeliversAttachments(t *testing.T) {
+	repo := new(MockMessageRepository)
+	attachments := new(MockAttachmentRepository)
+	m := NewManager(nil, repo, WithAttachmentRepository(attachments))
+	sender := newTestClient(m, "sender")
+
+	storedID := uuid.New()
+	upload := &model.Attachment{ID: uuid.New(), UploaderID: "sender", FileName: "loadout.png", ContentType: "image/png"}
+	linked := *upload
+	linked.MessageID = storedID.String()
+
+	repo.On("FindByClientID", "sender", "c-1").Return((*model.Message)(nil), nil).Once()
+	attachments.On("FindByID", upload.ID.String()).Return(upload, nil).Once()
+	repo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		stored := args.Get(0).(*model.Message)
+		stored.ID = storedID
+		stored.Attachments = []model.Attachment{linked}
+	}).Return(nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{
+		ReceiverID:  "receiver",
+		ClientID:    "c-1",
+		Attachments: []model.Attachment{{ID: upload.ID}},
+	}))
+
+	d := <-m.broadcast
+	delivered := decodeTestMessage(t, d.envelope)
+	assert.Len(t, delivered.Attachments, 1)
+	assert.Equal(t, "loadout.png", delivered.Attachments[0].FileName)
+	assert.Equal(t, storedID.String(), delivered.Attachments[0].MessageID)
+	assert.False(t, decodeTestAck(t, <-sender.send).Duplicate)
+	attachments.AssertExpectations(t)
+}
+
+func TestHandleMessageSendRejectsAttachmentsClaimedMeanwhile(t *testing.T) {
+	repo := new(MockMessageRepository)
+	attachments := new(MockAttachmentRepository)
+	m := NewManager(nil, repo, WithAttachmentRepository(attachments))
+	sender := newTestClient(m, "sender")
+
+	upload := &model.Attachment{ID: uuid.New(), UploaderID: "sender"}
+	repo.On("FindByClientID", "sender", "c-1").Return((*model.Message)(nil), nil)
+	attachments.On("FindByID", upload.ID.String()).Return(upload, nil).Once()
+	repo.On("Create", mock.Anything).Return(repository.ErrAttachmentUnavailable).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{
+		ReceiverID:  "receiver",
+		ClientID:    "c-1",
+		Attachments: []model.Attachment{{ID: upload.ID}},
+	}))
+
+	assert.Equal(t, ErrCodeNotFound, decodeTestError(t, <-sender.send).Code)
+	assert.Len(t, m.broadcast, 0)
+}
+
+func TestHandleMessageSendRejectsOthersAttachments(t *testing.T) {
+	repo := new(MockMessageRepository)
+	attachments := new(MockAttachmentRepository)
+	m := NewManager(nil, repo, WithAttachmentRepository(attachments))
+	sender := newTestClient(m, "sender")
+
+	upload := &model.Attachment{ID: uuid.New(), UploaderID: "someone-else"}
+	repo.On("FindByClientID", "sender", "c-1").Return((*model.Message)(nil), nil).Once()
+	attachments.On("FindByID", upload.ID.String()).Return(upload, nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{
+		ReceiverID:  "receiver",
+		ClientID:    "c-1",
+		Attachments: []model.Attachment{{ID: upload.ID}},
+	}))
+
+	assert.Equal(t, ErrCodeNotFound, decodeTestError(t, <-sender.send).Code)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestHandleMessageSendRejectsAttachmentsWhenUnsupported(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	sender := newTestClient(m, "sender")
+
+	repo.On("FindByClientID", "sender", "c-1").Return((*model.Message)(nil), nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{
+		ReceiverID:  "receiver",
+		ClientID:    "c-1",
+		Attachments: []model.Attachment{{ID: uuid.New()}},
+	}))
+
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-sender.send).Code)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
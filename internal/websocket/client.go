@@ -2,12 +2,15 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/mauFade/playzy/internal/dto"
 	"github.com/mauFade/playzy/internal/model"
 )
 
@@ -23,19 +26,108 @@ const (
 
 	// Maximum message size allowed from peer.
 	maxMessageSize = 512 * 1024 // 512KB
+
+	// Frames above maxMessageSize are answered with an error event; only frames
+	// above this hard limit make the connection close.
+	maxFrameSize = 2 * maxMessageSize
 )
 
 // Client representa uma conexão cliente
 type Client struct {
+	// id identifies this connection among the user's devices.
+	id      string
 	manager *Manager
 	conn    *websocket.Conn
-	send    chan model.Message
+	send    chan Envelope
 	mutex   sync.Mutex
 	userID  string
 
-	// New fields for connection management
-	lastPing time.Time
-	isAlive  bool
+	// sendMutex guards closed so envelopes can be queued from any goroutine
+	// without racing the manager closing the send channel.
+	sendMutex sync.Mutex
+	closed    bool
+
+	// holding keeps live envelopes in held while the connection catches up on
+	// missed messages, so the replay reaches the client first. Both are
+	// guarded by sendMutex.
+	holding bool
+	held    []Envelope
+
+	// catchUp tells the manager to replay missed messages once the connection
+	// is registered, starting after since when the client sent a cursor.
+	catchUp bool
+	since   *dto.MessageCursor
+
+	// Expiration of the token used on the handshake; zero means no expiry.
+	expiresAt time.Time
+
+	// New fields for connection management. stateMutex guards lastPing and
+	// away, which the presence sweep reads from the manager goroutine.
+	stateMutex sync.Mutex
+	lastPing   time.Time
+	isAlive    bool
+	away       bool
+
+	// lastTyping is when this connection last relayed a typing start, used to
+	// throttle indicators.
+	lastTyping time.Time
+}
+
+// enqueue queues an envelope for WritePump without blocking. It returns false
+// when the connection is already closed or its buffer is full.
+func (c *Client) enqueue(e Envelope) bool {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	if c.holding {
+		if len(c.held) >= maxHeldEnvelopes {
+			return false
+		}
+
+		c.held = append(c.held, e)
+		return true
+	}
+
+	select {
+	case c.send <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSend closes the send channel once, which makes WritePump close the
+// connection.
+func (c *Client) closeSend() {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// reply sends an envelope answering the request with the given ID.
+func (c *Client) reply(eventType, id string, payload any) {
+	e, err := newReply(eventType, id, payload)
+	if err != nil {
+		log.Printf("Erro ao codificar evento %s: %v", eventType, err)
+		return
+	}
+
+	if !c.enqueue(e) {
+		log.Printf("Evento %s descartado para a conexão %s", eventType, c.id)
+	}
+}
+
+// sendError reports a failure to handle the envelope with the given ID.
+func (c *Client) sendError(id, code, message string) {
+	c.reply(EventError, id, ErrorPayload{Code: code, Message: message})
 }
 
 // ReadPump lê mensagens da conexão WebSocket
@@ -49,18 +141,22 @@ func (c *Client) ReadPump() {
 	}()
 
 	// Configure connection
-	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadLimit(maxFrameSize)
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.stateMutex.Lock()
 		c.lastPing = time.Now()
+		c.stateMutex.Unlock()
 		return nil
 	})
 
 	for {
 		_, data, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if errors.Is(err, websocket.ErrReadLimit) {
+				log.Printf("Message too large from %s, closing connection", c.userID)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket read error: %v", err)
 			}
 			break
@@ -68,48 +164,53 @@ func (c *Client) ReadPump() {
 
 		// Validate message size
 		if len(data) > maxMessageSize {
-			log.Printf("Message too large: %d bytes", len(data))
+			c.sendError("", ErrCodePayloadTooLarge, fmt.Sprintf("message exceeds %d bytes", maxMessageSize))
 			continue
 		}
 
-		// Decodificar JSON
-		var message model.Message
-		if err := json.Unmarshal(data, &message); err != nil {
-			log.Printf("Erro ao decodificar mensagem: %v", err)
+		var envelope Envelope
+		if err := json.Unmarshal(data, &envelope); err != nil || envelope.Type == "" {
+			c.sendError(envelope.ID, ErrCodeInvalidEnvelope, "expected {\"type\", \"id\", \"payload\"}")
 			continue
 		}
 
-		// Validate message
-		if err := c.validateMessage(message); err != nil {
-			log.Printf("Invalid message: %v", err)
+		if v := c.manager.limiter.allowEvent(c.userID, envelope.Type, time.Now()); !v.allowed {
+			c.throttled(envelope, v)
 			continue
 		}
 
-		// Garantir que o remetente seja correto
-		message.SenderID = c.userID
-		message.Timestamp = time.Now()
-		message.IsRead = false
+		c.manager.dispatch(c, envelope)
+	}
+}
 
-		// Tente salvar a mensagem antes de broadcast
-		err = c.manager.repository.Create(message)
-		if err != nil {
-			log.Printf("Erro ao salvar mensagem no banco: %v", err)
-			// Continue mesmo com erro para não interromper o fluxo
-		}
+// throttled tells the client an event was dropped by the rate limiter.
+func (c *Client) throttled(e Envelope, v verdict) {
+	payload := ErrorPayload{
+		Code:         ErrCodeRateLimited,
+		Message:      fmt.Sprintf("too many %s events, slow down", e.Type),
+		RetryAfterMs: v.retryAfter.Milliseconds(),
+	}
 
-		// Enviar a mensagem para processamento
-		select {
-		case c.manager.broadcast <- message:
-			// Message sent successfully
-		default:
-			log.Printf("Broadcast channel full, message dropped")
-		}
+	if v.muted {
+		payload.Code = ErrCodeMuted
+		payload.Message = "muted for sending too fast"
 	}
+
+	c.reply(EventError, e.ID, payload)
 }
 
 // WritePump envia mensagens para a conexão WebSocket
 func (c *Client) WritePump() {
 	ticker := time.NewTicker(pingPeriod)
+
+	// Close the connection once the token used on the handshake expires.
+	var expired <-chan time.Time
+	if !c.expiresAt.IsZero() {
+		expiry := time.NewTimer(time.Until(c.expiresAt))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("Recovered from panic in WritePump: %v", r)
@@ -120,7 +221,7 @@ func (c *Client) WritePump() {
 
 	for {
 		select {
-		case message, ok := <-c.send:
+		case envelope, ok := <-c.send:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// The manager closed the channel.
@@ -128,10 +229,10 @@ func (c *Client) WritePump() {
 				return
 			}
 
-			// Converter a mensagem para JSON
-			data, err := json.Marshal(message)
+			// Converter o evento para JSON
+			data, err := json.Marshal(envelope)
 			if err != nil {
-				log.Printf("Erro ao codificar mensagem: %v", err)
+				log.Printf("Erro ao codificar evento: %v", err)
 				continue
 			}
 
@@ -148,17 +249,60 @@ func (c *Client) WritePump() {
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
+
+		case <-expired:
+			c.mutex.Lock()
+			c.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "token expired"),
+				time.Now().Add(writeWait),
+			)
+			c.mutex.Unlock()
+			return
 		}
 	}
 }
 
 // validateMessage validates the message before processing
 func (c *Client) validateMessage(msg model.Message) error {
-	if msg.Content == "" {
+	if msg.Content == "" && !msg.HasAttachments() {
 		return fmt.Errorf("empty message content")
 	}
-	if msg.ReceiverID == "" {
-		return fmt.Errorf("missing receiver ID")
+	if len(msg.Attachments) > maxAttachmentsPerMessage {
+		return fmt.Errorf("too many attachments, at most %d are allowed", maxAttachmentsPerMessage)
 	}
+	if msg.ReceiverID == "" && msg.ConversationID == "" {
+		return fmt.Errorf("missing receiver or conversation ID")
+	}
+	if msg.ReceiverID != "" && msg.ConversationID != "" {
+		return fmt.Errorf("message must be sent to a receiver or to a conversation, not both")
+	}
+	if msg.IsReply() {
+		return c.validateReply(msg)
+	}
+	return nil
+}
+
+// validateReply checks that the message being answered exists and belongs to
+// the same conversation. Membership of group conversations is checked when the
+// message is sent.
+func (c *Client) validateReply(msg model.Message) error {
+	if _, err := uuid.Parse(msg.ReplyToID); err != nil {
+		return fmt.Errorf("invalid reply target ID")
+	}
+
+	parent, err := c.manager.repository.FindByID(msg.ReplyToID)
+	if err != nil {
+		log.Printf("Erro ao buscar mensagem respondida %s: %v", msg.ReplyToID, err)
+		return fmt.Errorf("could not check reply target")
+	}
+
+	reply := msg
+	reply.SenderID = c.userID
+
+	if parent == nil || !reply.InConversationOf(parent) {
+		return fmt.Errorf("reply target not found in this conversation")
+	}
+
 	return nil
 }
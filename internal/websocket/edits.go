@@ -0,0 +1,37 @@
+package websocket
+
+import (
+	"encoding/json"
+)
+
+// handleMessageEdit changes the content of a message the client sent and
+// pushes the edited message to every participant.
+func (m *Manager) handleMessageEdit(c *Client, e Envelope) error {
+	var req MessageEditPayload
+	if err := json.Unmarshal(e.Payload, &req); err != nil {
+		return NewHandlerError(ErrCodeInvalidPayload, "invalid edit payload")
+	}
+
+	edited, err := m.actions.Edit(c.userID, req.MessageID, req.Content)
+	if err != nil {
+		return err
+	}
+
+	return m.publishUpdate(EventMessageEdited, edited, "message edited but the change could not be delivered")
+}
+
+// handleMessageDelete deletes a message the client sent for everyone and
+// pushes its tombstone to every participant.
+func (m *Manager) handleMessageDelete(c *Client, e Envelope) error {
+	var req MessageDeletePayload
+	if err := json.Unmarshal(e.Payload, &req); err != nil {
+		return NewHandlerError(ErrCodeInvalidPayload, "invalid delete payload")
+	}
+
+	deleted, err := m.actions.Delete(c.userID, req.MessageID)
+	if err != nil {
+		return err
+	}
+
+	return m.publishUpdate(EventMessageDeleted, deleted, "message deleted but the change could not be delivered")
+}
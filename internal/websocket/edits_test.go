@@ -0,0 +1,120 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMessageActions struct {
+	mock.Mock
+}
+
+func (m *MockMessageActions) Edit(userID, messageID, content string) (*MessageUpdate, error) {
+	args := m.Called(userID, messageID, content)
+	update, _ := args.Get(0).(*MessageUpdate)
+	return update, args.Error(1)
+}
+
+func (m *MockMessageActions) Delete(userID, messageID string) (*MessageUpdate, error) {
+	args := m.Called(userID, messageID)
+	update, _ := args.Get(0).(*MessageUpdate)
+	return update, args.Error(1)
+}
+
+func (m *MockMessageActions) AddReaction(userID, messageID, emoji string) (*MessageUpdate, error) {
+	args := m.Called(userID, messageID, emoji)
+	update, _ := args.Get(0).(*MessageUpdate)
+	return update, args.Error(1)
+}
+
+func (m *MockMessageActions) RemoveReaction(userID, messageID, emoji string) (*MessageUpdate, error) {
+	args := m.Called(userID, messageID, emoji)
+	update, _ := args.Get(0).(*MessageUpdate)
+	return update, args.Error(1)
+}
+
+func decodeTestChange(t *testing.T, e Envelope) model.Message {
+	t.Helper()
+
+	var message model.Message
+	assert.NoError(t, json.Unmarshal(e.Payload, &message))
+
+	return message
+}
+
+func TestHandleMessageEditPushesToParticipants(t *testing.T) {
+	actions := new(MockMessageActions)
+	m := NewManager(nil, new(MockMessageRepository), WithMessageActions(actions))
+	sender := newTestClient(m, "sender")
+
+	edited := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "sender", ReceiverID: "receiver", Timestamp: time.Now()}
+	edited.Edit("gg wp", time.Now())
+	actions.On("Edit", "sender", edited.ID.String(), "gg wp").Return(&MessageUpdate{
+		Payload:      edited,
+		Participants: []string{"sender", "receiver"},
+		Changed:      true,
+	}, nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageEdit, MessageEditPayload{MessageID: edited.ID.String(), Content: "gg wp"}))
+
+	d := <-m.broadcast
+	assert.ElementsMatch(t, []string{"sender", "receiver"}, d.userIDs)
+	assert.Equal(t, EventMessageEdited, d.envelope.Type)
+	pushed := decodeTestChange(t, d.envelope)
+	assert.Equal(t, "gg wp", pushed.Content)
+	assert.NotNil(t, pushed.EditedAt)
+	assert.Len(t, sender.send, 0)
+	actions.AssertExpectations(t)
+}
+
+func TestHandleMessageEditForwardsRefusal(t *testing.T) {
+	actions := new(MockMessageActions)
+	m := NewManager(nil, new(MockMessageRepository), WithMessageActions(actions))
+	receiver := newTestClient(m, "receiver")
+
+	id := uuid.NewString()
+	actions.On("Edit", "receiver", id, "ez").Return(nil, NewHandlerError(ErrCodeForbidden, "only the sender can change this message")).Once()
+
+	m.dispatch(receiver, testEnvelope(t, EventMessageEdit, MessageEditPayload{MessageID: id, Content: "ez"}))
+
+	assert.Equal(t, ErrCodeForbidden, decodeTestError(t, <-receiver.send).Code)
+	assert.Len(t, m.broadcast, 0)
+}
+
+func TestHandleMessageDeletePushesTombstone(t *testing.T) {
+	actions := new(MockMessageActions)
+	m := NewManager(nil, new(MockMessageRepository), WithMessageActions(actions))
+	sender := newTestClient(m, "sender")
+
+	deleted := &model.Message{ID: uuid.New(), Content: "discord.gg/secret", SenderID: "sender", ReceiverID: "receiver", Timestamp: time.Now()}
+	deleted.Tombstone(time.Now())
+	actions.On("Delete", "sender", deleted.ID.String()).Return(&MessageUpdate{
+		Payload:      deleted,
+		Participants: []string{"sender", "receiver"},
+		Changed:      true,
+	}, nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageDelete, MessageDeletePayload{MessageID: deleted.ID.String()}))
+
+	d := <-m.broadcast
+	assert.Equal(t, EventMessageDeleted, d.envelope.Type)
+	pushed := decodeTestChange(t, d.envelope)
+	assert.Empty(t, pushed.Content)
+	assert.NotNil(t, pushed.DeletedAt)
+	actions.AssertExpectations(t)
+}
+
+func TestHandleMessageEditWithoutActionsIsUnknown(t *testing.T) {
+	m := NewManager(nil, new(MockMessageRepository))
+	sender := newTestClient(m, "sender")
+
+	m.dispatch(sender, testEnvelope(t, EventMessageEdit, MessageEditPayload{MessageID: uuid.NewString(), Content: "gg"}))
+
+	assert.Equal(t, ErrCodeUnknownEvent, decodeTestError(t, <-sender.send).Code)
+}
@@ -0,0 +1,231 @@
+package websocket
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+)
+
+// ProtocolVersion is the envelope version spoken by this server. Clients may
+// omit it; any other value is rejected.
+const ProtocolVersion = 1
+
+// Event types carried by an Envelope.
+const (
+	// EventMessageSend is sent by a client to post a chat message.
+	EventMessageSend = "message.send"
+	// EventMessageNew delivers a stored chat message to its participants.
+	EventMessageNew = "message.new"
+	// EventMessageAck confirms to the sender that a message was stored.
+	EventMessageAck = "message.ack"
+	// EventMessageNack tells the sender a message was not accepted.
+	EventMessageNack = "message.nack"
+	// EventMessageRead is sent by a client to mark messages as read, and back
+	// to both participants as a read receipt.
+	EventMessageRead = "message.read"
+	// EventMessageEdit is sent by a client to change the content of a message
+	// it sent.
+	EventMessageEdit = "message.edit"
+	// EventMessageEdited pushes an edited message to both participants.
+	EventMessageEdited = "message.edited"
+	// EventMessageDelete is sent by a client to delete a message it sent for
+	// everyone.
+	EventMessageDelete = "message.delete"
+	// EventMessageDeleted pushes the tombstone of a deleted message to both
+	// participants.
+	EventMessageDeleted = "message.deleted"
+	// EventReactionAdd is sent by a client to react to a message.
+	EventReactionAdd = "reaction.add"
+	// EventReactionRemove is sent by a client to take back a reaction.
+	EventReactionRemove = "reaction.remove"
+	// EventReactionAdded pushes a new reaction and the updated counts of the
+	// message to both participants.
+	EventReactionAdded = "reaction.added"
+	// EventReactionRemoved pushes a removed reaction and the updated counts of
+	// the message to both participants.
+	EventReactionRemoved = "reaction.removed"
+	// EventTyping signals that a user started or stopped typing to someone. It
+	// is ephemeral and never stored.
+	EventTyping = "typing"
+	// EventPresence is sent by a client to flag itself away or back online,
+	// and pushed to conversation partners when a user's status changes.
+	EventPresence = "presence"
+	// EventSync is sent by a client to receive the messages it missed after a
+	// cursor. Missed messages are replayed as EventMessageNew envelopes.
+	EventSync = "sync"
+	// EventSyncDone follows a replay of missed messages, on connect and in
+	// answer to EventSync.
+	EventSyncDone = "sync.done"
+	// EventError reports a failure to handle an incoming envelope.
+	EventError = "error"
+)
+
+// Error codes sent in the payload of EventError envelopes.
+const (
+	ErrCodeInvalidEnvelope    = "invalid_envelope"
+	ErrCodeUnsupportedVersion = "unsupported_version"
+	ErrCodeUnknownEvent       = "unknown_event"
+	ErrCodeInvalidPayload     = "invalid_payload"
+	ErrCodePayloadTooLarge    = "payload_too_large"
+	ErrCodeNotFound           = "not_found"
+	ErrCodeForbidden          = "forbidden"
+	ErrCodeConflict           = "conflict"
+	ErrCodeUnsupported        = "unsupported"
+	ErrCodePersistFailed      = "persist_failed"
+	ErrCodeServerBusy         = "server_busy"
+	ErrCodeRateLimited        = "rate_limited"
+	ErrCodeMuted              = "muted"
+	ErrCodeInternal           = "internal_error"
+)
+
+// Envelope is the frame exchanged over the socket in both directions.
+type Envelope struct {
+	Version int             `json:"v"`
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// ErrorPayload is the payload of an EventError envelope.
+type ErrorPayload struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// RetryAfterMs tells throttled or muted clients when to try again.
+	RetryAfterMs int64 `json:"retryAfterMs,omitempty"`
+}
+
+// MessageAckPayload is the payload of an EventMessageAck envelope. It is only
+// sent once the message is stored.
+type MessageAckPayload struct {
+	ClientID  string    `json:"clientId,omitempty"`
+	MessageID string    `json:"messageId"`
+	Timestamp time.Time `json:"timestamp"`
+	// Duplicate is set when the client retried a message that was already
+	// stored; the original ID and timestamp are returned.
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// MessageNackPayload is the payload of an EventMessageNack envelope.
+type MessageNackPayload struct {
+	ClientID  string `json:"clientId,omitempty"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+	// RetryAfterMs hints how long the client should wait before retrying.
+	RetryAfterMs int64 `json:"retryAfterMs,omitempty"`
+}
+
+// MessageReadPayload is the payload of an EventMessageRead envelope sent by a
+// client: every message up to MessageID in that conversation is marked read.
+type MessageReadPayload struct {
+	MessageID string `json:"messageId"`
+}
+
+// MessageEditPayload is the payload of an EventMessageEdit envelope.
+type MessageEditPayload struct {
+	MessageID string `json:"messageId"`
+	Content   string `json:"content"`
+}
+
+// MessageDeletePayload is the payload of an EventMessageDelete envelope.
+type MessageDeletePayload struct {
+	MessageID string `json:"messageId"`
+}
+
+// SyncPayload is the payload of an EventSync envelope. Since is the cursor of
+// the last message the client has, as returned by the history endpoints or by
+// a previous EventSyncDone.
+type SyncPayload struct {
+	Since string `json:"since,omitempty"`
+}
+
+// SyncDonePayload is the payload of an EventSyncDone envelope. Cursor points
+// at the last replayed message; when HasMore is set the client should send an
+// EventSync with it to receive the rest.
+type SyncDonePayload struct {
+	Cursor  string `json:"cursor,omitempty"`
+	Count   int    `json:"count"`
+	HasMore bool   `json:"hasMore"`
+}
+
+// ReactionPayload is the payload of EventReactionAdd and EventReactionRemove
+// envelopes.
+type ReactionPayload struct {
+	MessageID string `json:"messageId"`
+	Emoji     string `json:"emoji"`
+}
+
+// ReadReceiptPayload is the payload of an EventMessageRead envelope pushed to
+// the participants of a conversation.
+type ReadReceiptPayload struct {
+	ReaderID  string    `json:"readerId"`
+	SenderID  string    `json:"senderId"`
+	MessageID string    `json:"messageId"`
+	ReadAt    time.Time `json:"readAt"`
+}
+
+// Typing states carried by TypingPayload.
+const (
+	TypingStart = "start"
+	TypingStop  = "stop"
+)
+
+// TypingPayload is the payload of an EventTyping envelope. Clients set To;
+// the server fills in From and, for TypingStart, how long the indicator lasts
+// without a refresh.
+type TypingPayload struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// ConversationID addresses a group conversation instead of a user.
+	ConversationID string `json:"conversationId,omitempty"`
+	State          string `json:"state"`
+	ExpiresInMs    int64  `json:"expiresInMs,omitempty"`
+}
+
+// PresencePayload is the payload of an EventPresence envelope sent by a client.
+type PresencePayload struct {
+	Status model.PresenceStatus `json:"status"`
+}
+
+// HandlerError is returned by an EventHandler to send a specific error code back
+// to the client. Any other error is reported as ErrCodeInternal.
+type HandlerError struct {
+	Code    string
+	Message string
+}
+
+func (e *HandlerError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// NewHandlerError builds a HandlerError with the given code and message.
+func NewHandlerError(code, message string) *HandlerError {
+	return &HandlerError{Code: code, Message: message}
+}
+
+// EventHandler handles an incoming envelope of a registered type on behalf of
+// the client that sent it.
+type EventHandler func(c *Client, e Envelope) error
+
+// NewEnvelope builds a server envelope with a fresh ID.
+func NewEnvelope(eventType string, payload any) (Envelope, error) {
+	return newReply(eventType, uuid.NewString(), payload)
+}
+
+// newReply builds a server envelope reusing the ID of the envelope it answers,
+// so clients can correlate acks and errors with their requests.
+func newReply(eventType, id string, payload any) (Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		Version: ProtocolVersion,
+		Type:    eventType,
+		ID:      id,
+		Payload: data,
+	}, nil
+}
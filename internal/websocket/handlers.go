@@ -0,0 +1,259 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/repository"
+)
+
+// retryAfterBusy is how long clients are asked to wait when the server sheds
+// load.
+const retryAfterBusy = time.Second
+
+// handleMessageSend stores a chat message sent by the client and delivers it to
+// every participant: both users of a one-to-one conversation, or every member
+// of a group. The sender gets an ack only after the message is stored,
+// or a nack when it can't be accepted. Retries carrying the same client ID are
+// acked with the stored message instead of being saved again.
+func (m *Manager) handleMessageSend(c *Client, e Envelope) error {
+	var message model.Message
+	if err := json.Unmarshal(e.Payload, &message); err != nil {
+		return NewHandlerError(ErrCodeInvalidPayload, "invalid message payload")
+	}
+
+	if err := c.validateMessage(message); err != nil {
+		return NewHandlerError(ErrCodeInvalidPayload, err.Error())
+	}
+
+	if message.ClientID == "" {
+		message.ClientID = e.ID
+	}
+
+	// Recusar antes de salvar se a fila de entrega estiver quase cheia
+	if m.saturated() {
+		c.reply(EventMessageNack, e.ID, MessageNackPayload{
+			ClientID:     message.ClientID,
+			Code:         ErrCodeServerBusy,
+			Message:      "server is busy, retry later",
+			Retryable:    true,
+			RetryAfterMs: retryAfterBusy.Milliseconds(),
+		})
+		return nil
+	}
+
+	recipients, err := m.resolveRecipients(c, &message)
+	if err != nil {
+		return err
+	}
+
+	if message.ClientID != "" {
+		stored, err := m.repository.FindByClientID(c.userID, message.ClientID)
+		if err != nil {
+			return m.nackPersistFailure(c, e.ID, message.ClientID, err)
+		}
+
+		if stored != nil {
+			m.ack(c, e.ID, stored, true)
+			return nil
+		}
+	}
+
+	if err := m.checkAttachments(c, message); err != nil {
+		return err
+	}
+
+	// Garantir que o remetente seja correto
+	message.SenderID = c.userID
+	message.Timestamp = time.Now()
+	message.IsRead = false
+
+	// Salvar a mensagem antes de confirmar e entregar
+	if err := m.repository.Create(&message); err != nil {
+		// A concurrent retry may have stored it first.
+		if message.ClientID != "" {
+			if stored, findErr := m.repository.FindByClientID(c.userID, message.ClientID); findErr == nil && stored != nil {
+				m.ack(c, e.ID, stored, true)
+				return nil
+			}
+		}
+
+		// Another message claimed one of the files since they were checked
+		if errors.Is(err, repository.ErrAttachmentUnavailable) {
+			return NewHandlerError(ErrCodeNotFound, "attachment not found")
+		}
+
+		return m.nackPersistFailure(c, e.ID, message.ClientID, err)
+	}
+
+	envelope, err := NewEnvelope(EventMessageNew, message)
+	if err != nil {
+		return err
+	}
+
+	if !m.publish(recipients, envelope) {
+		// The message is stored, so the receiver still gets it from history.
+		log.Printf("Broadcast channel full, message %s not delivered in real time", message.ID)
+	}
+
+	m.ack(c, e.ID, &message, false)
+	return nil
+}
+
+// resolveRecipients returns who a message must be delivered to. Group messages
+// are only accepted from members and while the conversation isn't archived;
+// one-to-one messages are filed under the direct conversation of both users.
+func (m *Manager) resolveRecipients(c *Client, message *model.Message) ([]string, error) {
+	if message.ConversationID == "" {
+		if m.conversations != nil {
+			conversation, err := m.conversations.FindOrCreateDirect(c.userID, message.ReceiverID)
+			if err != nil {
+				log.Printf("Erro ao buscar conversa direta: %v", err)
+				return nil, NewHandlerError(ErrCodeInvalidPayload, "invalid receiver ID")
+			}
+
+			message.ConversationID = conversation.ID.String()
+		}
+
+		return participants(c.userID, message.ReceiverID), nil
+	}
+
+	if m.conversations == nil {
+		return nil, NewHandlerError(ErrCodeNotFound, "conversation not found")
+	}
+
+	if _, err := uuid.Parse(message.ConversationID); err != nil {
+		return nil, NewHandlerError(ErrCodeInvalidPayload, "invalid conversation ID")
+	}
+
+	conversation, err := m.conversations.FindByID(message.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversation == nil {
+		return nil, NewHandlerError(ErrCodeNotFound, "conversation not found")
+	}
+
+	members, err := m.conversations.ListMemberIDs(message.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(members, c.userID) {
+		return nil, NewHandlerError(ErrCodeNotFound, "conversation not found")
+	}
+
+	if conversation.IsArchived() {
+		return nil, NewHandlerError(ErrCodeConflict, "conversation is archived")
+	}
+
+	return members, nil
+}
+
+func (m *Manager) ack(c *Client, id string, message *model.Message, duplicate bool) {
+	c.reply(EventMessageAck, id, MessageAckPayload{
+		ClientID:  message.ClientID,
+		MessageID: message.ID.String(),
+		Timestamp: message.Timestamp,
+		Duplicate: duplicate,
+	})
+}
+
+func (m *Manager) nackPersistFailure(c *Client, id, clientID string, err error) error {
+	log.Printf("Erro ao salvar mensagem no banco: %v", err)
+
+	c.reply(EventMessageNack, id, MessageNackPayload{
+		ClientID:  clientID,
+		Code:      ErrCodePersistFailed,
+		Message:   "could not save message",
+		Retryable: true,
+	})
+	return nil
+}
+
+// handleMessageRead marks every message the conversation partner sent to the
+// client up to the given message as read, and pushes a read receipt to both
+// participants so the sender and the reader's other devices stay in sync.
+// Read state is only kept for one-to-one messages, so group messages are
+// refused with ErrCodeUnsupported.
+func (m *Manager) handleMessageRead(c *Client, e Envelope) error {
+	var req MessageReadPayload
+	if err := json.Unmarshal(e.Payload, &req); err != nil {
+		return NewHandlerError(ErrCodeInvalidPayload, "invalid read payload")
+	}
+
+	if _, err := uuid.Parse(req.MessageID); err != nil {
+		return NewHandlerError(ErrCodeInvalidPayload, "invalid message ID")
+	}
+
+	message, err := m.repository.FindByID(req.MessageID)
+	if err != nil {
+		return err
+	}
+
+	if message != nil && message.IsGroup() {
+		return m.refuseGroupRead(c, message)
+	}
+
+	if message == nil || (message.SenderID != c.userID && message.ReceiverID != c.userID) {
+		return NewHandlerError(ErrCodeNotFound, "message not found")
+	}
+
+	senderID := message.SenderID
+	if senderID == c.userID {
+		senderID = message.ReceiverID
+	}
+
+	readAt := time.Now()
+
+	count, err := m.repository.MarkReadUpTo(c.userID, senderID, message.Timestamp, readAt)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		// Already read; nothing changed.
+		return nil
+	}
+
+	envelope, err := NewEnvelope(EventMessageRead, ReadReceiptPayload{
+		ReaderID:  c.userID,
+		SenderID:  senderID,
+		MessageID: message.ID.String(),
+		ReadAt:    readAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !m.publish(participants(senderID, c.userID), envelope) {
+		return NewHandlerError(ErrCodeServerBusy, "read state saved but receipt could not be delivered")
+	}
+
+	return nil
+}
+
+// refuseGroupRead answers a read mark on a group message, hiding it from users
+// outside the group.
+func (m *Manager) refuseGroupRead(c *Client, message *model.Message) error {
+	if m.conversations == nil {
+		return NewHandlerError(ErrCodeNotFound, "message not found")
+	}
+
+	members, err := m.conversations.ListMemberIDs(message.ConversationID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(members, c.userID) {
+		return NewHandlerError(ErrCodeNotFound, "message not found")
+	}
+
+	return NewHandlerError(ErrCodeUnsupported, "read receipts are only kept for one-to-one messages")
+}
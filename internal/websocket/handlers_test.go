@@ -0,0 +1,513 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageRepository) Create(msg *model.Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) FindByClientID(senderID, clientID string) (*model.Message, error) {
+	args := m.Called(senderID, clientID)
+	return args.Get(0).(*model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindByID(id string) (*model.Message, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) List(fstUserId, scdUserId string, page dto.MessagePage) ([]model.Message, error) {
+	args := m.Called(fstUserId, scdUserId, page)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) SetMessagesIsRead(userId, otherUserID string) error {
+	args := m.Called(userId, otherUserID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) MarkReadUpTo(readerID, senderID string, upTo, readAt time.Time) (int64, error) {
+	args := m.Called(readerID, senderID, upTo, readAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListConversationPartners(userID string) ([]string, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListInbox(userID string, limit, offset int) ([]dto.InboxEntry, int, error) {
+	args := m.Called(userID, limit, offset)
+	return args.Get(0).([]dto.InboxEntry), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) Edit(msg *model.Message, previousContent string) error {
+	args := m.Called(msg, previousContent)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) SoftDelete(msg *model.Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) FindByIDs(ids []string) ([]model.Message, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListByConversation(conversationID string, page dto.MessagePage) ([]model.Message, error) {
+	args := m.Called(conversationID, page)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) Search(userID, query string, before *dto.MessageCursor, limit int) ([]dto.MessageSearchHit, error) {
+	args := m.Called(userID, query, before, limit)
+	return args.Get(0).([]dto.MessageSearchHit), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListMissed(userID string, since *dto.MessageCursor, limit int) ([]model.Message, error) {
+	args := m.Called(userID, since, limit)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) MarkDelivered(receiverID string, ids []string, at time.Time) error {
+	args := m.Called(receiverID, ids, at)
+	return args.Error(0)
+}
+
+func testEnvelope(t *testing.T, eventType string, payload any) Envelope {
+	t.Helper()
+
+	data, err := json.Marshal(payload)
+	assert.NoError(t, err)
+
+	return Envelope{Version: ProtocolVersion, Type: eventType, ID: "req-1", Payload: data}
+}
+
+func decodeTestError(t *testing.T, e Envelope) ErrorPayload {
+	t.Helper()
+
+	var payload ErrorPayload
+	assert.Equal(t, EventError, e.Type)
+	assert.NoError(t, json.Unmarshal(e.Payload, &payload))
+
+	return payload
+}
+
+func TestDispatchUnknownEventReturnsError(t *testing.T) {
+	m := NewManager(nil, nil)
+	client := newTestClient(m, "player")
+
+	m.dispatch(client, Envelope{Type: "dance", ID: "req-1"})
+
+	got := <-client.send
+	assert.Equal(t, "req-1", got.ID)
+	assert.Equal(t, ErrCodeUnknownEvent, decodeTestError(t, got).Code)
+}
+
+func TestDispatchUnsupportedVersionReturnsError(t *testing.T) {
+	m := NewManager(nil, nil)
+	client := newTestClient(m, "player")
+
+	m.dispatch(client, Envelope{Version: ProtocolVersion + 1, Type: EventMessageSend, ID: "req-1"})
+
+	assert.Equal(t, ErrCodeUnsupportedVersion, decodeTestError(t, <-client.send).Code)
+}
+
+func TestDispatchRunsRegisteredHandler(t *testing.T) {
+	m := NewManager(nil, nil)
+	client := newTestClient(m, "player")
+
+	var handled Envelope
+	m.On("custom", func(c *Client, e Envelope) error {
+		handled = e
+		return nil
+	})
+	m.On("broken", func(c *Client, e Envelope) error {
+		return errors.New("boom")
+	})
+
+	m.dispatch(client, Envelope{Type: "custom", ID: "req-1"})
+	assert.Equal(t, "req-1", handled.ID)
+	assert.Len(t, client.send, 0)
+
+	m.dispatch(client, Envelope{Type: "broken", ID: "req-2"})
+	assert.Equal(t, ErrCodeInternal, decodeTestError(t, <-client.send).Code)
+}
+
+func decodeTestAck(t *testing.T, e Envelope) MessageAckPayload {
+	t.Helper()
+
+	var payload MessageAckPayload
+	assert.Equal(t, EventMessageAck, e.Type)
+	assert.NoError(t, json.Unmarshal(e.Payload, &payload))
+
+	return payload
+}
+
+func decodeTestNack(t *testing.T, e Envelope) MessageNackPayload {
+	t.Helper()
+
+	var payload MessageNackPayload
+	assert.Equal(t, EventMessageNack, e.Type)
+	assert.NoError(t, json.Unmarshal(e.Payload, &payload))
+
+	return payload
+}
+
+func TestHandleMessageSendStoresDeliversAndAcks(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	sender := newTestClient(m, "sender")
+
+	storedID := uuid.New()
+	storedAt := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	repo.On("FindByClientID", "sender", "c-1").Return((*model.Message)(nil), nil).Once()
+	repo.On("Create", mock.MatchedBy(func(msg *model.Message) bool {
+		return msg.SenderID == "sender" && msg.ReceiverID == "receiver" && msg.Content == "gg" && msg.ClientID == "c-1"
+	})).Run(func(args mock.Arguments) {
+		msg := args.Get(0).(*model.Message)
+		msg.ID = storedID
+		msg.Timestamp = storedAt
+	}).Return(nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{
+		Content:    "gg",
+		SenderID:   "someone-else",
+		ReceiverID: "receiver",
+		ClientID:   "c-1",
+	}))
+
+	d := <-m.broadcast
+	assert.ElementsMatch(t, []string{"sender", "receiver"}, d.userIDs)
+	delivered := decodeTestMessage(t, d.envelope)
+	assert.Equal(t, "sender", delivered.SenderID)
+	assert.Equal(t, storedID, delivered.ID)
+
+	got := <-sender.send
+	assert.Equal(t, "req-1", got.ID)
+	ack := decodeTestAck(t, got)
+	assert.Equal(t, "c-1", ack.ClientID)
+	assert.Equal(t, storedID.String(), ack.MessageID)
+	assert.True(t, storedAt.Equal(ack.Timestamp))
+	assert.False(t, ack.Duplicate)
+	repo.AssertExpectations(t)
+}
+
+func TestHandleMessageSendAcksRetryWithoutStoringTwice(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	sender := newTestClient(m, "sender")
+
+	stored := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "sender", ReceiverID: "receiver", ClientID: "c-1", Timestamp: time.Now()}
+	repo.On("FindByClientID", "sender", "c-1").Return(stored, nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "gg", ReceiverID: "receiver", ClientID: "c-1"}))
+
+	ack := decodeTestAck(t, <-sender.send)
+	assert.Equal(t, stored.ID.String(), ack.MessageID)
+	assert.True(t, ack.Duplicate)
+	assert.Len(t, m.broadcast, 0)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestHandleMessageSendReportsInvalidMessage(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	sender := newTestClient(m, "sender")
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "gg"}))
+
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-sender.send).Code)
+	assert.Len(t, m.broadcast, 0)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestHandleMessageSendNacksPersistFailure(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	sender := newTestClient(m, "sender")
+
+	repo.On("FindByClientID", "sender", "req-1").Return((*model.Message)(nil), nil)
+	repo.On("Create", mock.Anything).Return(errors.New("db down")).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "gg", ReceiverID: "receiver"}))
+
+	got := <-sender.send
+	assert.Equal(t, "req-1", got.ID)
+	nack := decodeTestNack(t, got)
+	assert.Equal(t, ErrCodePersistFailed, nack.Code)
+	assert.Equal(t, "req-1", nack.ClientID)
+	assert.True(t, nack.Retryable)
+	assert.Len(t, m.broadcast, 0)
+}
+
+func TestHandleMessageSendNacksWhenSaturated(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	sender := newTestClient(m, "sender")
+
+	for !m.saturated() {
+		m.broadcast <- delivery{}
+	}
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "gg", ReceiverID: "receiver"}))
+
+	nack := decodeTestNack(t, <-sender.send)
+	assert.Equal(t, ErrCodeServerBusy, nack.Code)
+	assert.True(t, nack.Retryable)
+	assert.Positive(t, nack.RetryAfterMs)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestHandleMessageReadMarksAndPushesReceipt(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	reader := newTestClient(m, "reader")
+
+	last := &model.Message{ID: uuid.New(), Content: "gg", SenderID: "sender", ReceiverID: "reader", Timestamp: time.Now()}
+	repo.On("FindByID", last.ID.String()).Return(last, nil).Once()
+	repo.On("MarkReadUpTo", "reader", "sender", last.Timestamp, mock.Anything).Return(int64(3), nil).Once()
+
+	m.dispatch(reader, testEnvelope(t, EventMessageRead, MessageReadPayload{MessageID: last.ID.String()}))
+
+	d := <-m.broadcast
+	assert.ElementsMatch(t, []string{"sender", "reader"}, d.userIDs)
+	assert.Equal(t, EventMessageRead, d.envelope.Type)
+
+	var receipt ReadReceiptPayload
+	assert.NoError(t, json.Unmarshal(d.envelope.Payload, &receipt))
+	assert.Equal(t, "reader", receipt.ReaderID)
+	assert.Equal(t, "sender", receipt.SenderID)
+	assert.Equal(t, last.ID.String(), receipt.MessageID)
+	assert.False(t, receipt.ReadAt.IsZero())
+	assert.Len(t, reader.send, 0)
+	repo.AssertExpectations(t)
+}
+
+func TestHandleMessageReadWithNothingNewSkipsReceipt(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	reader := newTestClient(m, "reader")
+
+	last := &model.Message{ID: uuid.New(), SenderID: "sender", ReceiverID: "reader", Timestamp: time.Now()}
+	repo.On("FindByID", last.ID.String()).Return(last, nil).Once()
+	repo.On("MarkReadUpTo", "reader", "sender", last.Timestamp, mock.Anything).Return(int64(0), nil).Once()
+
+	m.dispatch(reader, testEnvelope(t, EventMessageRead, MessageReadPayload{MessageID: last.ID.String()}))
+
+	assert.Len(t, m.broadcast, 0)
+	assert.Len(t, reader.send, 0)
+}
+
+func TestHandleMessageReadRejectsOtherConversations(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	outsider := newTestClient(m, "outsider")
+
+	private := &model.Message{ID: uuid.New(), SenderID: "sender", ReceiverID: "reader", Timestamp: time.Now()}
+	repo.On("FindByID", private.ID.String()).Return(private, nil).Once()
+
+	m.dispatch(outsider, testEnvelope(t, EventMessageRead, MessageReadPayload{MessageID: private.ID.String()}))
+
+	assert.Equal(t, ErrCodeNotFound, decodeTestError(t, <-outsider.send).Code)
+	repo.AssertNotCalled(t, "MarkReadUpTo", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleMessageReadRefusesGroupMessages(t *testing.T) {
+	repo := new(MockMessageRepository)
+	conversations := new(MockConversationRepository)
+	m := NewManager(nil, repo, WithConversationRepository(conversations))
+	member := newTestClient(m, "member")
+	outsider := newTestClient(m, "outsider")
+
+	conversationID := uuid.NewString()
+	group := &model.Message{ID: uuid.New(), SenderID: "sender", ConversationID: conversationID, Timestamp: time.Now()}
+	repo.On("FindByID", group.ID.String()).Return(group, nil).Twice()
+	conversations.On("ListMemberIDs", conversationID).Return([]string{"sender", "member"}, nil).Twice()
+
+	m.dispatch(member, testEnvelope(t, EventMessageRead, MessageReadPayload{MessageID: group.ID.String()}))
+	assert.Equal(t, ErrCodeUnsupported, decodeTestError(t, <-member.send).Code)
+
+	m.dispatch(outsider, testEnvelope(t, EventMessageRead, MessageReadPayload{MessageID: group.ID.String()}))
+	assert.Equal(t, ErrCodeNotFound, decodeTestError(t, <-outsider.send).Code)
+
+	assert.Len(t, m.broadcast, 0)
+	repo.AssertNotCalled(t, "MarkReadUpTo", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleMessageSendStoresReply(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	sender := newTestClient(m, "sender")
+
+	parent := &model.Message{ID: uuid.New(), Content: "what time?", SenderID: "receiver", ReceiverID: "sender"}
+	repo.On("FindByID", parent.ID.String()).Return(parent, nil).Once()
+	repo.On("FindByClientID", "sender", "req-1").Return((*model.Message)(nil), nil).Once()
+	repo.On("Create", mock.MatchedBy(func(msg *model.Message) bool {
+		return msg.ReplyToID == parent.ID.String()
+	})).Return(nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "9pm", ReceiverID: "receiver", ReplyToID: parent.ID.String()}))
+
+	assert.Equal(t, parent.ID.String(), decodeTestMessage(t, (<-m.broadcast).envelope).ReplyToID)
+	repo.AssertExpectations(t)
+}
+
+func TestHandleMessageSendRejectsReplyToOtherConversation(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	sender := newTestClient(m, "sender")
+
+	private := &model.Message{ID: uuid.New(), Content: "secret", SenderID: "someone", ReceiverID: "receiver"}
+	repo.On("FindByID", private.ID.String()).Return(private, nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "gg", ReceiverID: "receiver", ReplyToID: private.ID.String()}))
+
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-sender.send).Code)
+	assert.Len(t, m.broadcast, 0)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+type MockConversationRepository struct {
+	mock.Mock
+}
+
+func (m *MockConversationRepository) Create(c *model.Conversation, memberIDs []string) error {
+	args := m.Called(c, memberIDs)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) FindByID(id string) (*model.Conversation, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindDirect(userID, otherUserID string) (*model.Conversation, error) {
+	args := m.Called(userID, otherUserID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindOrCreateDirect(userID, otherUserID string) (*model.Conversation, error) {
+	args := m.Called(userID, otherUserID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) ListMemberIDs(conversationID string) ([]string, error) {
+	args := m.Called(conversationID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockConversationRepository) FindBySessionID(sessionID string) (*model.Conversation, error) {
+	args := m.Called(sessionID)
+	return args.Get(0).(*model.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) AddMember(conversationID, userID string) error {
+	args := m.Called(conversationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) RemoveMember(conversationID, userID string) error {
+	args := m.Called(conversationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) Archive(conversationID string, at time.Time) error {
+	args := m.Called(conversationID, at)
+	return args.Error(0)
+}
+
+func TestHandleMessageSendFansOutToGroupMembers(t *testing.T) {
+	repo := new(MockMessageRepository)
+	conversations := new(MockConversationRepository)
+	m := NewManager(nil, repo, WithConversationRepository(conversations))
+	sender := newTestClient(m, "sender")
+
+	conversationID := uuid.NewString()
+	conversations.On("FindByID", conversationID).Return(&model.Conversation{Kind: model.ConversationGroup}, nil).Once()
+	conversations.On("ListMemberIDs", conversationID).Return([]string{"sender", "duo", "trio"}, nil).Once()
+	repo.On("FindByClientID", "sender", "req-1").Return((*model.Message)(nil), nil).Once()
+	repo.On("Create", mock.MatchedBy(func(msg *model.Message) bool {
+		return msg.ConversationID == conversationID && msg.ReceiverID == ""
+	})).Return(nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "gl hf", ConversationID: conversationID}))
+
+	d := <-m.broadcast
+	assert.Equal(t, []string{"sender", "duo", "trio"}, d.userIDs)
+	assert.Equal(t, conversationID, decodeTestMessage(t, d.envelope).ConversationID)
+	assert.Equal(t, EventMessageAck, (<-sender.send).Type)
+	repo.AssertExpectations(t)
+}
+
+func TestHandleMessageSendRejectsNonMembers(t *testing.T) {
+	repo := new(MockMessageRepository)
+	conversations := new(MockConversationRepository)
+	m := NewManager(nil, repo, WithConversationRepository(conversations))
+	outsider := newTestClient(m, "outsider")
+
+	conversationID := uuid.NewString()
+	conversations.On("FindByID", conversationID).Return(&model.Conversation{Kind: model.ConversationGroup}, nil).Once()
+	conversations.On("ListMemberIDs", conversationID).Return([]string{"sender", "duo"}, nil).Once()
+
+	m.dispatch(outsider, testEnvelope(t, EventMessageSend, model.Message{Content: "hi", ConversationID: conversationID}))
+
+	assert.Equal(t, ErrCodeNotFound, decodeTestError(t, <-outsider.send).Code)
+	assert.Len(t, m.broadcast, 0)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestHandleMessageSendFilesDirectMessageUnderConversation(t *testing.T) {
+	repo := new(MockMessageRepository)
+	conversations := new(MockConversationRepository)
+	m := NewManager(nil, repo, WithConversationRepository(conversations))
+	sender := newTestClient(m, "sender")
+
+	direct := &model.Conversation{ID: uuid.New(), Kind: model.ConversationDirect}
+	conversations.On("FindOrCreateDirect", "sender", "receiver").Return(direct, nil).Once()
+	repo.On("FindByClientID", "sender", "req-1").Return((*model.Message)(nil), nil).Once()
+	repo.On("Create", mock.MatchedBy(func(msg *model.Message) bool {
+		return msg.ConversationID == direct.ID.String() && msg.ReceiverID == "receiver"
+	})).Return(nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "gg", ReceiverID: "receiver"}))
+
+	assert.ElementsMatch(t, []string{"sender", "receiver"}, (<-m.broadcast).userIDs)
+	repo.AssertExpectations(t)
+}
+
+func TestHandleMessageSendRejectsArchivedLobby(t *testing.T) {
+	repo := new(MockMessageRepository)
+	conversations := new(MockConversationRepository)
+	m := NewManager(nil, repo, WithConversationRepository(conversations))
+	sender := newTestClient(m, "sender")
+
+	archivedAt := time.Now()
+	conversationID := uuid.NewString()
+	conversations.On("FindByID", conversationID).Return(&model.Conversation{Kind: model.ConversationGroup, ArchivedAt: &archivedAt}, nil).Once()
+	conversations.On("ListMemberIDs", conversationID).Return([]string{"sender", "duo"}, nil).Once()
+
+	m.dispatch(sender, testEnvelope(t, EventMessageSend, model.Message{Content: "anyone?", ConversationID: conversationID}))
+
+	assert.Equal(t, ErrCodeConflict, decodeTestError(t, <-sender.send).Code)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
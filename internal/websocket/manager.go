@@ -2,27 +2,143 @@ package websocket
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/mauFade/playzy/internal/model"
 	"github.com/mauFade/playzy/internal/repository"
 )
 
+// defaultMaxConnectionsPerUser is how many devices a user may keep connected at
+// the same time when no limit is configured.
+const defaultMaxConnectionsPerUser = 5
+
 // Manager gerencia todas as conexões WebSocket
 type Manager struct {
-	clients    map[string]*Client
-	broadcast  chan model.Message
+	// clients indexes connections by userID and then by connection ID, so a
+	// user can stay connected from several devices at once.
+	clients    map[string]map[string]*Client
+	broadcast  chan delivery
 	register   chan *Client
 	unregister chan *Client
 	mutex      sync.RWMutex
 	db         *sql.DB
 	repository repository.MessageRepositoryInterface
+	users      repository.UserRepositoryInterface
+	// actions edits, deletes and reacts to messages. Without it those events
+	// are refused.
+	actions MessageActions
+	// conversations resolves group members and the direct conversation of new
+	// one-to-one messages. Without it only one-to-one messages are accepted.
+	conversations repository.ConversationRepositoryInterface
+	// pubsub shares deliveries with the managers of other instances.
+	pubsub PubSub
+	// attachments links uploaded files to the messages they are shared in.
+	// Without it messages with attachments are refused.
+	attachments repository.AttachmentRepositoryInterface
+
+	// limiter throttles connection attempts and incoming events per user.
+	limiter *rateLimiter
+
+	maxConnectionsPerUser int
+
+	// handlers maps each incoming event type to its EventHandler.
+	handlers      map[string]EventHandler
+	handlersMutex sync.RWMutex
+
+	// typing holds the expiry timer of every active typing indicator.
+	typing        map[typingKey]*time.Timer
+	typingMutex   sync.Mutex
+	typingTimeout time.Duration
+
+	// presence holds the status of every user connected to this instance and
+	// remotePresence the statuses shared by the other instances, by instance
+	// ID. Both are guarded by presenceMutex, which is never held while taking
+	// another lock.
+	presence       map[string]model.PresenceStatus
+	remotePresence map[string]*instancePresence
+	presenceSeq    uint64
+	presenceMutex  sync.Mutex
+	// instanceID tells the presence this instance shares apart from the
+	// others'.
+	instanceID string
+}
+
+// delivery is an envelope addressed to every connection of a set of users.
+type delivery struct {
+	userIDs  []string
+	envelope Envelope
+}
+
+// Option customizes a Manager created by NewManager.
+type Option func(*Manager)
+
+// WithUserRepository lets the manager persist when users were last seen.
+func WithUserRepository(users repository.UserRepositoryInterface) Option {
+	return func(m *Manager) {
+		m.users = users
+	}
+}
+
+// WithConversationRepository enables group conversations and files new
+// one-to-one messages under their direct conversation.
+func WithConversationRepository(conversations repository.ConversationRepositoryInterface) Option {
+	return func(m *Manager) {
+		m.conversations = conversations
+	}
+}
+
+// WithPubSub shares deliveries through the given backend, so users connected
+// to different instances reach each other. An in-memory backend is used by
+// default.
+func WithPubSub(pubsub PubSub) Option {
+	return func(m *Manager) {
+		m.pubsub = pubsub
+	}
+}
+
+// WithAttachmentRepository enables sharing uploaded files in messages.
+func WithAttachmentRepository(attachments repository.AttachmentRepositoryInterface) Option {
+	return func(m *Manager) {
+		m.attachments = attachments
+	}
+}
+
+// WithMessageActions enables editing, deleting and reacting to messages over
+// the socket.
+func WithMessageActions(actions MessageActions) Option {
+	return func(m *Manager) {
+		m.actions = actions
+		m.On(EventMessageEdit, m.handleMessageEdit)
+		m.On(EventMessageDelete, m.handleMessageDelete)
+		m.On(EventReactionAdd, m.handleReactionAdd)
+		m.On(EventReactionRemove, m.handleReactionRemove)
+	}
+}
+
+// WithRateLimits replaces the default limits on connection attempts and
+// incoming events.
+func WithRateLimits(limits RateLimits) Option {
+	return func(m *Manager) {
+		m.limiter = newRateLimiter(limits)
+	}
+}
 
-	rateLimiter map[string]time.Time
+// WithMaxConnectionsPerUser caps how many concurrent connections a single user
+// may hold. Non-positive values keep the default.
+func WithMaxConnectionsPerUser(max int) Option {
+	return func(m *Manager) {
+		if max > 0 {
+			m.maxConnectionsPerUser = max
+		}
+	}
 }
 
 // Configuração do upgrader
@@ -42,57 +158,263 @@ var upgrader = websocket.Upgrader{
 	},
 	// Add handshake timeout
 	HandshakeTimeout: 10 * time.Second,
+	// Echo the token subprotocol back so browsers accept the handshake.
+	Subprotocols: []string{tokenSubprotocol},
 }
 
 // NewManager cria um novo gerenciador
-func NewManager(db *sql.DB, repo repository.MessageRepositoryInterface) *Manager {
-	return &Manager{
-		clients:     make(map[string]*Client),
-		broadcast:   make(chan model.Message, 1000), // Increased buffer size
-		register:    make(chan *Client, 100),
-		unregister:  make(chan *Client, 100),
-		mutex:       sync.RWMutex{},
-		db:          db,
-		repository:  repo,
-		rateLimiter: make(map[string]time.Time),
+func NewManager(db *sql.DB, repo repository.MessageRepositoryInterface, opts ...Option) *Manager {
+	m := &Manager{
+		clients:               make(map[string]map[string]*Client),
+		broadcast:             make(chan delivery, 1000), // Increased buffer size
+		register:              make(chan *Client, 100),
+		unregister:            make(chan *Client, 100),
+		mutex:                 sync.RWMutex{},
+		db:                    db,
+		repository:            repo,
+		limiter:               newRateLimiter(DefaultRateLimits()),
+		maxConnectionsPerUser: defaultMaxConnectionsPerUser,
+		handlers:              make(map[string]EventHandler),
+		typing:                make(map[typingKey]*time.Timer),
+		typingTimeout:         defaultTypingTimeout,
+		presence:              make(map[string]model.PresenceStatus),
+		remotePresence:        make(map[string]*instancePresence),
+		instanceID:            uuid.NewString(),
+	}
+
+	m.On(EventMessageSend, m.handleMessageSend)
+	m.On(EventMessageRead, m.handleMessageRead)
+	m.On(EventTyping, m.handleTyping)
+	m.On(EventPresence, m.handlePresence)
+	m.On(EventSync, m.handleSync)
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.pubsub == nil {
+		m.pubsub = NewMemoryPubSub()
 	}
+	m.pubsub.Subscribe(m.receive)
+
+	return m
 }
 
 // Start inicia o gerenciador em uma goroutine separada
 func (m *Manager) Start() {
+	sweep := time.NewTicker(presenceSweepInterval)
+	defer sweep.Stop()
+
+	limiterSweep := time.NewTicker(limiterSweepInterval)
+	defer limiterSweep.Stop()
+
 	for {
 		select {
 		case client := <-m.register:
-			// Registrar um novo cliente pelo userID
+			// Registrar uma nova conexão do usuário
 			m.mutex.Lock()
-			m.clients[client.userID] = client
+			if !m.addClient(client) {
+				// The user reached the connection cap after ServeWs checked it.
+				client.closeSend()
+				m.mutex.Unlock()
+				log.Printf("Cliente %s recusado: limite de conexões atingido", client.userID)
+				continue
+			}
+			total := len(m.clients[client.userID])
 			m.mutex.Unlock()
-			log.Printf("Cliente %s conectado. Total: %d", client.userID, len(m.clients))
+			log.Printf("Cliente %s conectado (conexão %s). Dispositivos: %d", client.userID, client.id, total)
+
+			// Live envelopes are held from now on until the replay is queued
+			if client.catchUp {
+				go m.catchUp(client, client.since)
+			}
 
 		case client := <-m.unregister:
-			// Desregistrar um cliente
+			// Desregistrar uma conexão
 			m.mutex.Lock()
-			if _, ok := m.clients[client.userID]; ok {
-				delete(m.clients, client.userID)
-				close(client.send)
-			}
+			m.removeClient(client)
+			total := len(m.clients[client.userID])
 			m.mutex.Unlock()
-			log.Printf("Cliente %s desconectado. Total: %d", client.userID, len(m.clients))
+			log.Printf("Cliente %s desconectado (conexão %s). Dispositivos: %d", client.userID, client.id, total)
 
-		case message := <-m.broadcast:
-			// Enviar mensagem para todos os clientes
+		case d := <-m.broadcast:
 			m.mutex.Lock()
-			for _, client := range m.clients {
-				select {
-				case client.send <- message:
-					// Mensagem enviada com sucesso
-				default:
-					// Cliente não consegue receber mensagens
-					close(client.send)
-					delete(m.clients, client.userID)
-				}
+			m.deliver(d)
+			receiverID, messageID, delivered := m.deliveredTo(d)
+			m.mutex.Unlock()
+
+			// Saving the delivery must not hold up the connections
+			if delivered {
+				go m.markDelivered(receiverID, messageID)
+			}
+
+		case now := <-sweep.C:
+			// Connections that stopped answering pings turn away
+			m.mutex.Lock()
+			for userID := range m.clients {
+				m.refreshPresence(userID)
 			}
 			m.mutex.Unlock()
+
+			go m.sharePresence(m.presenceSnapshot(now))
+
+		case now := <-limiterSweep.C:
+			m.limiter.sweep(now)
 		}
 	}
 }
+
+// addClient registers a connection for its user, refusing it when the user is
+// already at the connection cap. The caller must hold m.mutex.
+func (m *Manager) addClient(client *Client) bool {
+	conns, ok := m.clients[client.userID]
+	if !ok {
+		conns = make(map[string]*Client)
+		m.clients[client.userID] = conns
+	}
+
+	if len(conns) >= m.maxConnectionsPerUser {
+		return false
+	}
+
+	conns[client.id] = client
+	m.refreshPresence(client.userID)
+	return true
+}
+
+// removeClient unregisters a connection and closes its send channel. It is a
+// no-op for connections that are no longer registered. The caller must hold
+// m.mutex.
+func (m *Manager) removeClient(client *Client) {
+	conns, ok := m.clients[client.userID]
+	if !ok {
+		return
+	}
+
+	if current, ok := conns[client.id]; !ok || current != client {
+		return
+	}
+
+	delete(conns, client.id)
+	if len(conns) == 0 {
+		delete(m.clients, client.userID)
+	}
+	client.closeSend()
+	m.refreshPresence(client.userID)
+}
+
+// atConnectionLimit reports whether the user can't open another connection.
+func (m *Manager) atConnectionLimit(userID string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return len(m.clients[userID]) >= m.maxConnectionsPerUser
+}
+
+// On registers the handler for an incoming event type, replacing any handler
+// previously registered for it.
+func (m *Manager) On(eventType string, handler EventHandler) {
+	m.handlersMutex.Lock()
+	defer m.handlersMutex.Unlock()
+
+	m.handlers[eventType] = handler
+}
+
+// dispatch runs the handler registered for the envelope type and reports any
+// failure back to the client as an error event.
+func (m *Manager) dispatch(c *Client, e Envelope) {
+	if e.Version != 0 && e.Version != ProtocolVersion {
+		c.sendError(e.ID, ErrCodeUnsupportedVersion, fmt.Sprintf("protocol version %d is not supported", e.Version))
+		return
+	}
+
+	m.handlersMutex.RLock()
+	handler, ok := m.handlers[e.Type]
+	m.handlersMutex.RUnlock()
+
+	if !ok {
+		c.sendError(e.ID, ErrCodeUnknownEvent, fmt.Sprintf("unknown event type %q", e.Type))
+		return
+	}
+
+	if err := handler(c, e); err != nil {
+		var handlerErr *HandlerError
+		if errors.As(err, &handlerErr) {
+			c.sendError(e.ID, handlerErr.Code, handlerErr.Message)
+			return
+		}
+
+		log.Printf("Erro ao processar evento %s: %v", e.Type, err)
+		c.sendError(e.ID, ErrCodeInternal, "could not process event")
+	}
+}
+
+// publish queues an envelope for every connection of the given users, on this
+// instance and through the pub/sub on the others. It returns false when it
+// couldn't be queued.
+func (m *Manager) publish(userIDs []string, e Envelope) bool {
+	payload, err := json.Marshal(wireDelivery{UserIDs: userIDs, Envelope: e})
+	if err != nil {
+		log.Printf("Erro ao codificar entrega %s: %v", e.Type, err)
+		return false
+	}
+
+	if err := m.pubsub.Publish(payload); err != nil {
+		if !errors.Is(err, errBroadcastFull) {
+			log.Printf("Erro ao publicar entrega %s: %v", e.Type, err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// Notify pushes an event to every connection of the given users. It lets code
+// outside the socket, such as REST handlers, keep connected clients in sync.
+func (m *Manager) Notify(userIDs []string, eventType string, payload any) error {
+	e, err := NewEnvelope(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	if !m.publish(userIDs, e) {
+		return errors.New("could not publish event")
+	}
+
+	return nil
+}
+
+// saturated reports whether the broadcast queue is close to full, in which case
+// new chat messages are refused up front instead of being dropped.
+func (m *Manager) saturated() bool {
+	return len(m.broadcast) >= cap(m.broadcast)*9/10
+}
+
+// deliver sends an envelope only to the connections of the users it is
+// addressed to. Chat messages are addressed to every device of the receiver
+// and of the sender, so that the sender's other devices stay in sync. The
+// caller must hold m.mutex.
+func (m *Manager) deliver(d delivery) {
+	for _, userID := range d.userIDs {
+		for _, client := range m.clients[userID] {
+			if !client.enqueue(d.envelope) {
+				// Cliente não consegue receber mensagens
+				m.removeClient(client)
+			}
+		}
+	}
+}
+
+// recipients returns the distinct user IDs a message must be delivered to.
+func recipients(message model.Message) []string {
+	return participants(message.ReceiverID, message.SenderID)
+}
+
+// participants returns the distinct user IDs of a one-to-one conversation.
+func participants(userID, otherUserID string) []string {
+	if userID == otherUserID {
+		return []string{userID}
+	}
+
+	return []string{userID, otherUserID}
+}
@@ -0,0 +1,205 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(m *Manager, userID string) *Client {
+	return &Client{
+		id:       uuid.NewString(),
+		manager:  m,
+		send:     make(chan Envelope, 8),
+		userID:   userID,
+		isAlive:  true,
+		lastPing: time.Now(),
+	}
+}
+
+func testMessageDelivery(t *testing.T, message model.Message) delivery {
+	t.Helper()
+
+	envelope, err := NewEnvelope(EventMessageNew, message)
+	assert.NoError(t, err)
+
+	return delivery{userIDs: recipients(message), envelope: envelope}
+}
+
+func decodeTestMessage(t *testing.T, e Envelope) model.Message {
+	t.Helper()
+
+	var message model.Message
+	assert.Equal(t, EventMessageNew, e.Type)
+	assert.NoError(t, json.Unmarshal(e.Payload, &message))
+
+	return message
+}
+
+func connectTestClients(m *Manager, clients ...*Client) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, c := range clients {
+		m.addClient(c)
+	}
+}
+
+func TestManagerDeliverOnlyToConversationParticipants(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	sender := newTestClient(m, "sender")
+	receiver := newTestClient(m, "receiver")
+	outsider := newTestClient(m, "outsider")
+	connectTestClients(m, sender, receiver, outsider)
+
+	message := model.Message{Content: "gg", SenderID: "sender", ReceiverID: "receiver"}
+	m.deliver(testMessageDelivery(t, message))
+
+	assert.Len(t, receiver.send, 1)
+	assert.Len(t, sender.send, 1)
+	assert.Len(t, outsider.send, 0)
+	assert.Equal(t, message, decodeTestMessage(t, <-receiver.send))
+}
+
+func TestManagerDeliverToEveryDeviceOfParticipants(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	senderDesktop := newTestClient(m, "sender")
+	senderPhone := newTestClient(m, "sender")
+	receiverDesktop := newTestClient(m, "receiver")
+	receiverPhone := newTestClient(m, "receiver")
+	outsider := newTestClient(m, "outsider")
+	connectTestClients(m, senderDesktop, senderPhone, receiverDesktop, receiverPhone, outsider)
+
+	m.deliver(testMessageDelivery(t, model.Message{Content: "gg", SenderID: "sender", ReceiverID: "receiver"}))
+
+	assert.Len(t, senderDesktop.send, 1)
+	assert.Len(t, senderPhone.send, 1)
+	assert.Len(t, receiverDesktop.send, 1)
+	assert.Len(t, receiverPhone.send, 1)
+	assert.Len(t, outsider.send, 0)
+}
+
+func TestManagerDeliverToOfflineReceiver(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	sender := newTestClient(m, "sender")
+	outsider := newTestClient(m, "outsider")
+	connectTestClients(m, sender, outsider)
+
+	m.deliver(testMessageDelivery(t, model.Message{Content: "gg", SenderID: "sender", ReceiverID: "receiver"}))
+
+	assert.Len(t, sender.send, 1)
+	assert.Len(t, outsider.send, 0)
+}
+
+func TestManagerDeliverMessageToSelfOnce(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	client := newTestClient(m, "me")
+	connectTestClients(m, client)
+
+	m.deliver(testMessageDelivery(t, model.Message{Content: "note", SenderID: "me", ReceiverID: "me"}))
+
+	assert.Len(t, client.send, 1)
+}
+
+func TestManagerDeliverDropsSlowClient(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	slow := &Client{id: uuid.NewString(), manager: m, send: make(chan Envelope), userID: "receiver"}
+	healthy := newTestClient(m, "receiver")
+	connectTestClients(m, slow, healthy)
+
+	m.deliver(testMessageDelivery(t, model.Message{Content: "gg", SenderID: "sender", ReceiverID: "receiver"}))
+
+	assert.Len(t, m.clients["receiver"], 1)
+	assert.Len(t, healthy.send, 1)
+	_, open := <-slow.send
+	assert.False(t, open)
+}
+
+func TestManagerConnectionLimitPerUser(t *testing.T) {
+	m := NewManager(nil, nil, WithMaxConnectionsPerUser(2))
+
+	first := newTestClient(m, "player")
+	second := newTestClient(m, "player")
+	third := newTestClient(m, "player")
+
+	m.mutex.Lock()
+	assert.True(t, m.addClient(first))
+	assert.True(t, m.addClient(second))
+	assert.False(t, m.addClient(third))
+	m.mutex.Unlock()
+
+	assert.True(t, m.atConnectionLimit("player"))
+	assert.False(t, m.atConnectionLimit("someone-else"))
+}
+
+func TestManagerStartRoutesBroadcastByRecipient(t *testing.T) {
+	m := NewManager(nil, nil)
+	go m.Start()
+
+	sender := newTestClient(m, "sender")
+	receiver := newTestClient(m, "receiver")
+	outsider := newTestClient(m, "outsider")
+
+	m.register <- sender
+	m.register <- receiver
+	m.register <- outsider
+
+	assert.Eventually(t, func() bool {
+		m.mutex.RLock()
+		defer m.mutex.RUnlock()
+		return len(m.clients) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	m.broadcast <- testMessageDelivery(t, model.Message{Content: "gg", SenderID: "sender", ReceiverID: "receiver"})
+
+	select {
+	case got := <-receiver.send:
+		assert.Equal(t, "gg", decodeTestMessage(t, got).Content)
+	case <-time.After(time.Second):
+		t.Fatal("receiver did not get the message")
+	}
+
+	select {
+	case <-sender.send:
+	case <-time.After(time.Second):
+		t.Fatal("sender did not get the echo")
+	}
+
+	select {
+	case got := <-outsider.send:
+		t.Fatalf("outsider received a private message: %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestManagerUnregisterKeepsOtherDevices(t *testing.T) {
+	m := NewManager(nil, nil)
+	go m.Start()
+
+	desktop := newTestClient(m, "player")
+	phone := newTestClient(m, "player")
+	connectTestClients(m, desktop, phone)
+
+	m.unregister <- desktop
+
+	_, open := <-desktop.send
+	assert.False(t, open)
+
+	m.broadcast <- testMessageDelivery(t, model.Message{Content: "still here", SenderID: "other", ReceiverID: "player"})
+
+	select {
+	case got := <-phone.send:
+		assert.Equal(t, "still here", decodeTestMessage(t, got).Content)
+	case <-time.After(time.Second):
+		t.Fatal("remaining device was unregistered")
+	}
+}
@@ -0,0 +1,221 @@
+package websocket
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// pubSubChannel is the Postgres channel deliveries are notified on.
+	pubSubChannel = "playzy_ws"
+
+	// maxNotifyPayload keeps notifications under the 8000 byte limit of
+	// NOTIFY. Larger payloads go through websocket_outbox.
+	maxNotifyPayload = 7900
+
+	// outboxRetention is how long large payloads are kept for slow listeners.
+	outboxRetention = time.Minute
+
+	// listenerPingInterval checks the listening connection is still alive
+	// when no notification arrives.
+	listenerPingInterval = 90 * time.Second
+)
+
+// Notification kinds: the payload itself, or the ID of the outbox row
+// holding it.
+const (
+	notifyInline = "p"
+	notifyRef    = "r"
+)
+
+// PostgresPubSub shares deliveries between instances through Postgres
+// LISTEN/NOTIFY. Payloads are handed to local subscribers right away and
+// notified to the other instances, which skip their own notifications.
+type PostgresPubSub struct {
+	db       *sql.DB
+	listener *pq.Listener
+	// origin identifies this instance in the notifications it sends.
+	origin string
+
+	handlersMutex sync.RWMutex
+	handlers      []func(payload []byte) error
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPostgresPubSub starts listening for deliveries on a dedicated connection
+// opened with connStr; notifications are sent through db, whose schema must be
+// migrated.
+func NewPostgresPubSub(db *sql.DB, connStr string) (*PostgresPubSub, error) {
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Erro na conexão LISTEN do pub/sub: %v", err)
+		}
+	})
+
+	if err := listener.Listen(pubSubChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	p := &PostgresPubSub{
+		db:       db,
+		listener: listener,
+		origin:   uuid.NewString(),
+		done:     make(chan struct{}),
+	}
+
+	go p.listen()
+
+	return p, nil
+}
+
+// Publish delivers the payload locally and notifies the other instances. Only
+// a local failure is returned: when the notification can't be sent it is
+// logged, and receivers connected elsewhere recover the delivery on reconnect.
+func (p *PostgresPubSub) Publish(payload []byte) error {
+	localErr := p.dispatch(payload)
+
+	if err := p.notify(payload); err != nil {
+		log.Printf("Erro ao notificar outras instâncias: %v", err)
+	}
+
+	return localErr
+}
+
+// notify sends the payload to the other instances, through the outbox when
+// it is too large for NOTIFY.
+func (p *PostgresPubSub) notify(payload []byte) error {
+	kind, data := notifyInline, string(payload)
+
+	if len(p.origin)+len(payload)+4 > maxNotifyPayload {
+		ref, err := p.store(payload)
+		if err != nil {
+			return err
+		}
+
+		kind, data = notifyRef, ref
+	}
+
+	_, err := p.db.Exec(`SELECT pg_notify($1, $2)`, pubSubChannel, encodeNotification(p.origin, kind, data))
+
+	return err
+}
+
+func (p *PostgresPubSub) Subscribe(handler func(payload []byte) error) {
+	p.handlersMutex.Lock()
+	defer p.handlersMutex.Unlock()
+
+	p.handlers = append(p.handlers, handler)
+}
+
+func (p *PostgresPubSub) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+
+	return p.listener.Close()
+}
+
+func (p *PostgresPubSub) dispatch(payload []byte) error {
+	p.handlersMutex.RLock()
+	defer p.handlersMutex.RUnlock()
+
+	var first error
+	for _, handler := range p.handlers {
+		if err := handler(payload); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// store keeps a payload too large for NOTIFY in the outbox, dropping the
+// rows every listener had time to read.
+func (p *PostgresPubSub) store(payload []byte) (string, error) {
+	if _, err := p.db.Exec(`DELETE FROM websocket_outbox WHERE created_at < $1`, time.Now().Add(-outboxRetention)); err != nil {
+		log.Printf("Erro ao limpar websocket_outbox: %v", err)
+	}
+
+	ref := uuid.NewString()
+
+	_, err := p.db.Exec(`INSERT INTO websocket_outbox (id, payload) VALUES ($1, $2)`, ref, string(payload))
+
+	return ref, err
+}
+
+func (p *PostgresPubSub) listen() {
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case n := <-p.listener.Notify:
+			if n == nil {
+				// The connection was re-established; anything notified
+				// meanwhile is lost and clients recover it on reconnect.
+				log.Printf("Conexão LISTEN do pub/sub restabelecida")
+				continue
+			}
+
+			p.receive(n.Extra)
+
+		case <-ping.C:
+			go func() {
+				if err := p.listener.Ping(); err != nil {
+					log.Printf("Erro ao verificar conexão LISTEN do pub/sub: %v", err)
+				}
+			}()
+
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// receive hands a notification of another instance to the local subscribers.
+func (p *PostgresPubSub) receive(raw string) {
+	origin, kind, data, err := decodeNotification(raw)
+	if err != nil {
+		log.Printf("Notificação do pub/sub inválida: %v", err)
+		return
+	}
+
+	if origin == p.origin {
+		return
+	}
+
+	if kind == notifyRef {
+		if err := p.db.QueryRow(`SELECT payload FROM websocket_outbox WHERE id = $1`, data).Scan(&data); err != nil {
+			log.Printf("Erro ao ler entrega %s do websocket_outbox: %v", data, err)
+			return
+		}
+	}
+
+	if err := p.dispatch([]byte(data)); err != nil {
+		log.Printf("Entrega de outra instância descartada: %v", err)
+	}
+}
+
+// encodeNotification formats a notification as "origin|kind|data".
+func encodeNotification(origin, kind, data string) string {
+	return origin + "|" + kind + "|" + data
+}
+
+func decodeNotification(raw string) (origin, kind, data string, err error) {
+	parts := strings.SplitN(raw, "|", 3)
+	if len(parts) != 3 || (parts[1] != notifyInline && parts[1] != notifyRef) {
+		return "", "", "", fmt.Errorf("malformed notification %.40q", raw)
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
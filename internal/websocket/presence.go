@@ -0,0 +1,279 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mauFade/playzy/internal/model"
+)
+
+const (
+	// A connection that missed a pong for this long counts as away, e.g. a
+	// phone that went to sleep with the socket still open.
+	awayAfter = pingPeriod + 15*time.Second
+
+	// How often connection health is checked for away transitions, and every
+	// instance shares the full presence of its users.
+	presenceSweepInterval = 15 * time.Second
+
+	// presenceExpiry forgets the users of an instance that stopped sharing
+	// its presence, e.g. because it crashed.
+	presenceExpiry = 3 * presenceSweepInterval
+)
+
+// presenceUpdate is the presence an instance shares with the others. Updates
+// are published concurrently, so Seq orders the ones of an instance and older
+// ones are dropped; the next full update makes up for them.
+type presenceUpdate struct {
+	Instance string `json:"instance"`
+	Seq      uint64 `json:"seq"`
+	// Users maps user IDs to their status on the instance. A full update lists
+	// every user connected there, otherwise only the ones that changed.
+	Users map[string]model.PresenceStatus `json:"users"`
+	Full  bool                            `json:"full,omitempty"`
+}
+
+// instancePresence is the presence another instance shared last.
+type instancePresence struct {
+	seq    uint64
+	users  map[string]model.PresenceStatus
+	seenAt time.Time
+}
+
+// presenceStatus returns the status of a single connection.
+func (c *Client) presenceStatus(now time.Time) model.PresenceStatus {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if c.away || now.Sub(c.lastPing) > awayAfter {
+		return model.PresenceAway
+	}
+
+	return model.PresenceOnline
+}
+
+func (c *Client) setAway(away bool) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	c.away = away
+}
+
+// Presence returns the status of a user: online if any of their connections is
+// active, away if all of them are idle and offline when none is open, on this
+// instance or any other.
+func (m *Manager) Presence(userID string) model.PresenceStatus {
+	m.presenceMutex.Lock()
+	defer m.presenceMutex.Unlock()
+
+	return m.combinedPresence(userID)
+}
+
+// combinedPresence returns the most present status a user has across the
+// instances. The caller must hold m.presenceMutex.
+func (m *Manager) combinedPresence(userID string) model.PresenceStatus {
+	status, ok := m.presence[userID]
+	if !ok {
+		status = model.PresenceOffline
+	}
+
+	for _, remote := range m.remotePresence {
+		if other, ok := remote.users[userID]; ok && presenceRank(other) > presenceRank(status) {
+			status = other
+		}
+	}
+
+	return status
+}
+
+func presenceRank(status model.PresenceStatus) int {
+	switch status {
+	case model.PresenceOnline:
+		return 2
+	case model.PresenceAway:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// refreshPresence recomputes the status of a user from their connections,
+// shares it with the other instances and announces it when the user's status
+// across instances changed. The caller must hold m.mutex.
+func (m *Manager) refreshPresence(userID string) {
+	now := time.Now()
+	status := model.PresenceOffline
+
+	for _, client := range m.clients[userID] {
+		if client.presenceStatus(now) == model.PresenceOnline {
+			status = model.PresenceOnline
+			break
+		}
+		status = model.PresenceAway
+	}
+
+	m.presenceMutex.Lock()
+
+	previous, ok := m.presence[userID]
+	if !ok {
+		previous = model.PresenceOffline
+	}
+
+	if status == previous {
+		m.presenceMutex.Unlock()
+		return
+	}
+
+	before := m.combinedPresence(userID)
+
+	if status == model.PresenceOffline {
+		delete(m.presence, userID)
+	} else {
+		m.presence[userID] = status
+	}
+
+	after := m.combinedPresence(userID)
+	update := m.nextPresenceUpdate(map[string]model.PresenceStatus{userID: status}, false)
+
+	m.presenceMutex.Unlock()
+
+	// Database work must not hold up the manager loop
+	go m.sharePresence(update)
+
+	if after != before {
+		go m.announcePresence(model.Presence{UserID: userID, Status: after, LastSeenAt: &now})
+	}
+}
+
+// presenceSnapshot forgets the instances that stopped sharing their presence
+// and returns a full update of this one. Users of a forgotten instance turn
+// offline without an announcement.
+func (m *Manager) presenceSnapshot(now time.Time) presenceUpdate {
+	m.presenceMutex.Lock()
+	defer m.presenceMutex.Unlock()
+
+	for instance, remote := range m.remotePresence {
+		if now.Sub(remote.seenAt) > presenceExpiry {
+			delete(m.remotePresence, instance)
+		}
+	}
+
+	users := make(map[string]model.PresenceStatus, len(m.presence))
+	for userID, status := range m.presence {
+		users[userID] = status
+	}
+
+	return m.nextPresenceUpdate(users, true)
+}
+
+// nextPresenceUpdate numbers an update of this instance. The caller must hold
+// m.presenceMutex.
+func (m *Manager) nextPresenceUpdate(users map[string]model.PresenceStatus, full bool) presenceUpdate {
+	m.presenceSeq++
+
+	return presenceUpdate{Instance: m.instanceID, Seq: m.presenceSeq, Users: users, Full: full}
+}
+
+// sharePresence publishes an update of this instance to the others.
+func (m *Manager) sharePresence(u presenceUpdate) {
+	payload, err := json.Marshal(wireDelivery{Presence: &u})
+	if err != nil {
+		log.Printf("Erro ao codificar presença: %v", err)
+		return
+	}
+
+	if err := m.pubsub.Publish(payload); err != nil && !errors.Is(err, errBroadcastFull) {
+		log.Printf("Erro ao compartilhar presença: %v", err)
+	}
+}
+
+// receivePresence records the presence shared by another instance.
+func (m *Manager) receivePresence(u presenceUpdate) {
+	if u.Instance == m.instanceID {
+		return
+	}
+
+	m.presenceMutex.Lock()
+	defer m.presenceMutex.Unlock()
+
+	remote, ok := m.remotePresence[u.Instance]
+	if !ok {
+		remote = &instancePresence{users: make(map[string]model.PresenceStatus)}
+		m.remotePresence[u.Instance] = remote
+	} else if u.Seq <= remote.seq {
+		return
+	}
+
+	remote.seq = u.Seq
+	remote.seenAt = time.Now()
+
+	if u.Full {
+		remote.users = make(map[string]model.PresenceStatus, len(u.Users))
+	}
+
+	for userID, status := range u.Users {
+		if status == model.PresenceOffline {
+			delete(remote.users, userID)
+		} else {
+			remote.users[userID] = status
+		}
+	}
+}
+
+// announcePresence persists when the user was last seen and pushes the new
+// status to everyone who has a conversation with them. Announcements run
+// concurrently, so clients should keep the one with the latest lastSeenAt.
+func (m *Manager) announcePresence(p model.Presence) {
+	if m.users != nil {
+		if err := m.users.UpdateLastSeen(p.UserID, *p.LastSeenAt); err != nil {
+			log.Printf("Erro ao salvar last_seen_at de %s: %v", p.UserID, err)
+		}
+	}
+
+	if m.repository == nil {
+		return
+	}
+
+	partners, err := m.repository.ListConversationPartners(p.UserID)
+	if err != nil {
+		log.Printf("Erro ao buscar conversas de %s: %v", p.UserID, err)
+		return
+	}
+
+	if len(partners) == 0 {
+		return
+	}
+
+	envelope, err := NewEnvelope(EventPresence, p)
+	if err != nil {
+		return
+	}
+
+	m.publish(partners, envelope)
+}
+
+// handlePresence lets a client flag itself away (e.g. the tab lost focus) or
+// back online.
+func (m *Manager) handlePresence(c *Client, e Envelope) error {
+	var req PresencePayload
+	if err := json.Unmarshal(e.Payload, &req); err != nil {
+		return NewHandlerError(ErrCodeInvalidPayload, "invalid presence payload")
+	}
+
+	switch req.Status {
+	case model.PresenceAway:
+		c.setAway(true)
+	case model.PresenceOnline:
+		c.setAway(false)
+	default:
+		return NewHandlerError(ErrCodeInvalidPayload, "presence status must be online or away")
+	}
+
+	m.mutex.Lock()
+	m.refreshPresence(c.userID)
+	m.mutex.Unlock()
+
+	return nil
+}
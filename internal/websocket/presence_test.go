@@ -0,0 +1,199 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) FindByID(id string) (*model.UserModel, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.UserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*model.UserModel, error) {
+	args := m.Called(email)
+	return args.Get(0).(*model.UserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByPhone(phone string) (*model.UserModel, error) {
+	args := m.Called(phone)
+	return args.Get(0).(*model.UserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByGamertag(gamertag string) (*model.UserModel, error) {
+	args := m.Called(gamertag)
+	return args.Get(0).(*model.UserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByIDs(ids []string) ([]*model.UserModel, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]*model.UserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) Create(user *model.UserModel) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateLastSeen(id string, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func nextTestPresence(t *testing.T, m *Manager) (delivery, model.Presence) {
+	t.Helper()
+
+	select {
+	case d := <-m.broadcast:
+		var p model.Presence
+		assert.Equal(t, EventPresence, d.envelope.Type)
+		assert.NoError(t, json.Unmarshal(d.envelope.Payload, &p))
+		return d, p
+	case <-time.After(time.Second):
+		t.Fatal("presence was not announced")
+		return delivery{}, model.Presence{}
+	}
+}
+
+func TestPresenceFollowsConnections(t *testing.T) {
+	repo := new(MockMessageRepository)
+	users := new(MockUserRepository)
+	m := NewManager(nil, repo, WithUserRepository(users))
+
+	repo.On("ListConversationPartners", "player").Return([]string{"friend"}, nil)
+	lastSeenSaved := make(chan struct{}, 2)
+	users.On("UpdateLastSeen", "player", mock.Anything).Run(func(mock.Arguments) {
+		lastSeenSaved <- struct{}{}
+	}).Return(nil)
+
+	desktop := newTestClient(m, "player")
+	phone := newTestClient(m, "player")
+
+	assert.Equal(t, model.PresenceOffline, m.Presence("player"))
+
+	connectTestClients(m, desktop, phone)
+	assert.Equal(t, model.PresenceOnline, m.Presence("player"))
+
+	d, p := nextTestPresence(t, m)
+	assert.Equal(t, []string{"friend"}, d.userIDs)
+	assert.Equal(t, model.PresenceOnline, p.Status)
+
+	m.mutex.Lock()
+	m.removeClient(desktop)
+	m.mutex.Unlock()
+	assert.Equal(t, model.PresenceOnline, m.Presence("player"))
+
+	m.mutex.Lock()
+	m.removeClient(phone)
+	m.mutex.Unlock()
+	assert.Equal(t, model.PresenceOffline, m.Presence("player"))
+
+	_, p = nextTestPresence(t, m)
+	assert.Equal(t, model.PresenceOffline, p.Status)
+	assert.NotNil(t, p.LastSeenAt)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-lastSeenSaved:
+		case <-time.After(time.Second):
+			t.Fatal("last seen was not saved")
+		}
+	}
+}
+
+func TestPresenceAwayWhenEveryDeviceIsAway(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	desktop := newTestClient(m, "player")
+	phone := newTestClient(m, "player")
+	connectTestClients(m, desktop, phone)
+
+	m.dispatch(desktop, testEnvelope(t, EventPresence, PresencePayload{Status: model.PresenceAway}))
+	assert.Equal(t, model.PresenceOnline, m.Presence("player"))
+
+	// The phone went to sleep and stopped answering pings
+	phone.stateMutex.Lock()
+	phone.lastPing = time.Now().Add(-2 * awayAfter)
+	phone.stateMutex.Unlock()
+
+	m.mutex.Lock()
+	m.refreshPresence("player")
+	m.mutex.Unlock()
+	assert.Equal(t, model.PresenceAway, m.Presence("player"))
+
+	m.dispatch(desktop, testEnvelope(t, EventPresence, PresencePayload{Status: model.PresenceOnline}))
+	assert.Equal(t, model.PresenceOnline, m.Presence("player"))
+}
+
+func TestHandlePresenceRejectsUnknownStatus(t *testing.T) {
+	m := NewManager(nil, nil)
+	client := newTestClient(m, "player")
+
+	m.dispatch(client, testEnvelope(t, EventPresence, PresencePayload{Status: model.PresenceOffline}))
+
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-client.send).Code)
+}
+
+func TestPresenceSharedBetweenInstances(t *testing.T) {
+	repo := new(MockMessageRepository)
+	repo.On("ListConversationPartners", "player").Return([]string{"friend"}, nil)
+
+	pubsub := NewMemoryPubSub()
+	first := NewManager(nil, repo, WithPubSub(pubsub))
+	second := NewManager(nil, repo, WithPubSub(pubsub))
+
+	desktop := newTestClient(first, "player")
+	connectTestClients(first, desktop)
+
+	assert.Eventually(t, func() bool {
+		return second.Presence("player") == model.PresenceOnline
+	}, time.Second, 10*time.Millisecond)
+
+	// The announcement reaches both instances through the pub/sub
+	_, p := nextTestPresence(t, first)
+	assert.Equal(t, model.PresenceOnline, p.Status)
+	nextTestPresence(t, second)
+
+	phone := newTestClient(second, "player")
+	connectTestClients(second, phone)
+
+	assert.Eventually(t, func() bool {
+		first.presenceMutex.Lock()
+		defer first.presenceMutex.Unlock()
+
+		remote, ok := first.remotePresence[second.instanceID]
+		return ok && remote.users["player"] == model.PresenceOnline
+	}, time.Second, 10*time.Millisecond)
+
+	first.mutex.Lock()
+	first.removeClient(desktop)
+	first.mutex.Unlock()
+
+	assert.Equal(t, model.PresenceOnline, first.Presence("player"))
+
+	select {
+	case d := <-first.broadcast:
+		t.Fatalf("presence announced while online on another instance: %+v", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPresenceForgetsSilentInstances(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	m.receivePresence(presenceUpdate{Instance: "other", Seq: 2, Users: map[string]model.PresenceStatus{"player": model.PresenceAway}, Full: true})
+	m.receivePresence(presenceUpdate{Instance: "other", Seq: 1, Users: map[string]model.PresenceStatus{"player": model.PresenceOnline}})
+	assert.Equal(t, model.PresenceAway, m.Presence("player"))
+
+	m.presenceSnapshot(time.Now().Add(presenceExpiry + time.Second))
+	assert.Equal(t, model.PresenceOffline, m.Presence("player"))
+}
@@ -0,0 +1,91 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// PubSub carries deliveries between the managers of every API instance, so
+// users connected to different instances can talk to each other. Payloads
+// are UTF-8 JSON documents.
+type PubSub interface {
+	// Publish sends the payload to every subscriber, including the ones of
+	// this instance.
+	Publish(payload []byte) error
+	// Subscribe registers a function receiving every published payload.
+	Subscribe(handler func(payload []byte) error)
+	Close() error
+}
+
+// errBroadcastFull is returned when the local delivery queue can't take more
+// envelopes.
+var errBroadcastFull = errors.New("broadcast queue is full")
+
+// wireDelivery is how a delivery, or the presence shared by an instance,
+// travels through the PubSub.
+type wireDelivery struct {
+	UserIDs  []string        `json:"userIds"`
+	Envelope Envelope        `json:"envelope"`
+	Presence *presenceUpdate `json:"presence,omitempty"`
+}
+
+// receive queues a delivery published by any instance for the local
+// connections of its users, and records the presence other instances share.
+func (m *Manager) receive(payload []byte) error {
+	var w wireDelivery
+	if err := json.Unmarshal(payload, &w); err != nil {
+		return err
+	}
+
+	if w.Presence != nil {
+		m.receivePresence(*w.Presence)
+		return nil
+	}
+
+	select {
+	case m.broadcast <- delivery{userIDs: w.UserIDs, envelope: w.Envelope}:
+		return nil
+	default:
+		return errBroadcastFull
+	}
+}
+
+// MemoryPubSub delivers payloads to the subscribers of the same process. It
+// is the default for a single instance and lets tests wire several managers
+// together.
+type MemoryPubSub struct {
+	mutex    sync.RWMutex
+	handlers []func(payload []byte) error
+}
+
+func NewMemoryPubSub() *MemoryPubSub {
+	return &MemoryPubSub{}
+}
+
+// Publish hands the payload to every subscriber and returns the first error
+// any of them reported.
+func (p *MemoryPubSub) Publish(payload []byte) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var first error
+	for _, handler := range p.handlers {
+		if err := handler(payload); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+func (p *MemoryPubSub) Subscribe(handler func(payload []byte) error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.handlers = append(p.handlers, handler)
+}
+
+func (p *MemoryPubSub) Close() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishReachesManagersOfOtherInstances(t *testing.T) {
+	pubsub := NewMemoryPubSub()
+	first := NewManager(nil, nil, WithPubSub(pubsub))
+	second := NewManager(nil, nil, WithPubSub(pubsub))
+
+	d := testMessageDelivery(t, model.Message{Content: "gg", SenderID: "sender", ReceiverID: "receiver"})
+	assert.True(t, first.publish(d.userIDs, d.envelope))
+
+	for _, m := range []*Manager{first, second} {
+		got := <-m.broadcast
+		assert.ElementsMatch(t, d.userIDs, got.userIDs)
+		assert.Equal(t, "gg", decodeTestMessage(t, got.envelope).Content)
+	}
+}
+
+func TestPublishFailsWhenLocalQueueIsFull(t *testing.T) {
+	m := NewManager(nil, nil)
+	for len(m.broadcast) < cap(m.broadcast) {
+		m.broadcast <- delivery{}
+	}
+
+	d := testMessageDelivery(t, model.Message{Content: "gg", SenderID: "sender", ReceiverID: "receiver"})
+	assert.False(t, m.publish(d.userIDs, d.envelope))
+}
+
+func TestNotificationRoundTrip(t *testing.T) {
+	origin, kind, data, err := decodeNotification(encodeNotification("instance-1", notifyInline, `{"userIds":["a|b"]}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "instance-1", origin)
+	assert.Equal(t, notifyInline, kind)
+	assert.Equal(t, `{"userIds":["a|b"]}`, data)
+
+	_, _, _, err = decodeNotification("garbage")
+	assert.Error(t, err)
+
+	_, _, _, err = decodeNotification("instance-1|x|{}")
+	assert.Error(t, err)
+}
@@ -0,0 +1,249 @@
+package websocket
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// limiterSweepInterval is how often idle limiter entries are evicted.
+const limiterSweepInterval = time.Minute
+
+// connectLimitKey is the bucket of connection attempts; it never collides
+// with event types, which contain a dot or are known single words.
+const connectLimitKey = "#connect"
+
+// RateLimit is a token bucket: up to Burst events at once, refilled at Rate
+// events per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits configures how often each user may act over the socket. Limits
+// apply to the user across all of their connections.
+type RateLimits struct {
+	// Messages limits chat messages sent.
+	Messages RateLimit
+	// Events limits each other event type; types missing here share Default.
+	Events  map[string]RateLimit
+	Default RateLimit
+	// Connections limits connection attempts.
+	Connections RateLimit
+	// MuteAfter throttled events within ViolationWindow mute the user for
+	// MuteFor: they can't send anything other users would see. Zero disables
+	// muting.
+	MuteAfter       int
+	ViolationWindow time.Duration
+	MuteFor         time.Duration
+	// IdleTTL is how long entries of inactive users are kept.
+	IdleTTL time.Duration
+}
+
+// DefaultRateLimits returns the limits used when none are configured.
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		Messages: RateLimit{Rate: 1, Burst: 5},
+		Events: map[string]RateLimit{
+			EventTyping:         {Rate: 2, Burst: 5},
+			EventPresence:       {Rate: 1, Burst: 3},
+			EventMessageRead:    {Rate: 5, Burst: 10},
+			EventMessageEdit:    {Rate: 1, Burst: 5},
+			EventMessageDelete:  {Rate: 1, Burst: 5},
+			EventReactionAdd:    {Rate: 3, Burst: 10},
+			EventReactionRemove: {Rate: 3, Burst: 10},
+			EventSync:           {Rate: 1, Burst: 3},
+		},
+		Default:         RateLimit{Rate: 5, Burst: 10},
+		Connections:     RateLimit{Rate: 1, Burst: 3},
+		MuteAfter:       5,
+		ViolationWindow: 30 * time.Second,
+		MuteFor:         time.Minute,
+		IdleTTL:         10 * time.Minute,
+	}
+}
+
+// mutedEvents are the events refused while a user is muted: everything that
+// reaches other users.
+var mutedEvents = map[string]bool{
+	EventMessageSend: true,
+	EventMessageEdit: true,
+	EventReactionAdd: true,
+	EventTyping:      true,
+}
+
+// verdict is the outcome of checking an event against the limits.
+type verdict struct {
+	allowed bool
+	muted   bool
+	// retryAfter is how long until the event would be allowed.
+	retryAfter time.Duration
+}
+
+type limiterKey struct {
+	userID string
+	bucket string
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// offender tracks the recent violations of a user.
+type offender struct {
+	violations []time.Time
+	mutedUntil time.Time
+}
+
+// rateLimiter applies RateLimits with one token bucket per user and event
+// type. It is safe for concurrent use.
+type rateLimiter struct {
+	limits RateLimits
+
+	mutex     sync.Mutex
+	buckets   map[limiterKey]*bucket
+	offenders map[string]*offender
+}
+
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	if limits.IdleTTL <= 0 {
+		limits.IdleTTL = DefaultRateLimits().IdleTTL
+	}
+
+	return &rateLimiter{
+		limits:    limits,
+		buckets:   make(map[limiterKey]*bucket),
+		offenders: make(map[string]*offender),
+	}
+}
+
+// allowEvent checks an incoming event. Throttled events count as violations,
+// and enough of them mute the user.
+func (l *rateLimiter) allowEvent(userID, eventType string, now time.Time) verdict {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if o, ok := l.offenders[userID]; ok && mutedEvents[eventType] && now.Before(o.mutedUntil) {
+		return verdict{muted: true, retryAfter: o.mutedUntil.Sub(now)}
+	}
+
+	name, limit := l.limitFor(eventType)
+
+	v := l.take(limiterKey{userID: userID, bucket: name}, limit, now)
+	if v.allowed {
+		return v
+	}
+
+	if l.violate(userID, now) {
+		log.Printf("Usuário %s silenciado por %s após exceder os limites", userID, l.limits.MuteFor)
+
+		if mutedEvents[eventType] {
+			return verdict{muted: true, retryAfter: l.limits.MuteFor}
+		}
+	}
+
+	return v
+}
+
+// allowConnection checks a connection attempt. It doesn't count toward muting.
+func (l *rateLimiter) allowConnection(userID string, now time.Time) verdict {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.take(limiterKey{userID: userID, bucket: connectLimitKey}, l.limits.Connections, now)
+}
+
+// limitFor returns the bucket name and limit of an event type. Unknown types
+// share the default bucket so clients can't grow the limiter at will.
+func (l *rateLimiter) limitFor(eventType string) (string, RateLimit) {
+	if eventType == EventMessageSend {
+		return eventType, l.limits.Messages
+	}
+
+	if limit, ok := l.limits.Events[eventType]; ok {
+		return eventType, limit
+	}
+
+	return "", l.limits.Default
+}
+
+// take removes a token from the bucket, refilling it for the time elapsed
+// since it was last used. The caller must hold l.mutex.
+func (l *rateLimiter) take(key limiterKey, limit RateLimit, now time.Time) verdict {
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		return verdict{allowed: true}
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return verdict{allowed: true}
+	}
+
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+
+	return verdict{retryAfter: wait}
+}
+
+// violate records a throttled event and reports whether it got the user
+// muted. The caller must hold l.mutex.
+func (l *rateLimiter) violate(userID string, now time.Time) bool {
+	if l.limits.MuteAfter <= 0 {
+		return false
+	}
+
+	o, ok := l.offenders[userID]
+	if !ok {
+		o = &offender{}
+		l.offenders[userID] = o
+	}
+
+	recent := o.violations[:0]
+	for _, at := range o.violations {
+		if now.Sub(at) < l.limits.ViolationWindow {
+			recent = append(recent, at)
+		}
+	}
+	o.violations = append(recent, now)
+
+	if len(o.violations) < l.limits.MuteAfter {
+		return false
+	}
+
+	o.violations = nil
+	o.mutedUntil = now.Add(l.limits.MuteFor)
+
+	return true
+}
+
+// sweep evicts buckets unused for IdleTTL and offenders with no recent
+// violation and no active mute.
+func (l *rateLimiter) sweep(now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > l.limits.IdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+
+	for userID, o := range l.offenders {
+		stale := len(o.violations) == 0 || now.Sub(o.violations[len(o.violations)-1]) > l.limits.ViolationWindow
+		if stale && !now.Before(o.mutedUntil) {
+			delete(l.offenders, userID)
+		}
+	}
+}
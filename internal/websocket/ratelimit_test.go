@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRateLimits() RateLimits {
+	return RateLimits{
+		Messages:        RateLimit{Rate: 1, Burst: 2},
+		Events:          map[string]RateLimit{EventTyping: {Rate: 1, Burst: 1}},
+		Default:         RateLimit{Rate: 1, Burst: 1},
+		Connections:     RateLimit{Rate: 1, Burst: 1},
+		MuteAfter:       3,
+		ViolationWindow: 10 * time.Second,
+		MuteFor:         time.Minute,
+		IdleTTL:         time.Minute,
+	}
+}
+
+func TestRateLimiterRefillsTokens(t *testing.T) {
+	l := newRateLimiter(testRateLimits())
+	now := time.Now()
+
+	assert.True(t, l.allowEvent("player", EventMessageSend, now).allowed)
+	assert.True(t, l.allowEvent("player", EventMessageSend, now).allowed)
+
+	v := l.allowEvent("player", EventMessageSend, now)
+	assert.False(t, v.allowed)
+	assert.False(t, v.muted)
+	assert.Equal(t, time.Second, v.retryAfter)
+
+	assert.True(t, l.allowEvent("player", EventMessageSend, now.Add(time.Second)).allowed)
+}
+
+func TestRateLimiterKeepsBucketsPerUserAndEventType(t *testing.T) {
+	l := newRateLimiter(testRateLimits())
+	now := time.Now()
+
+	assert.True(t, l.allowEvent("player", EventTyping, now).allowed)
+	assert.False(t, l.allowEvent("player", EventTyping, now).allowed)
+	assert.True(t, l.allowEvent("player", EventMessageSend, now).allowed)
+	assert.True(t, l.allowEvent("other", EventTyping, now).allowed)
+
+	// Unknown types share one bucket
+	assert.True(t, l.allowEvent("player", "made.up", now).allowed)
+	assert.False(t, l.allowEvent("player", "also.made.up", now).allowed)
+}
+
+func TestRateLimiterMutesRepeatedOffenders(t *testing.T) {
+	l := newRateLimiter(testRateLimits())
+	now := time.Now()
+
+	l.allowEvent("player", EventMessageSend, now)
+	l.allowEvent("player", EventMessageSend, now)
+	assert.False(t, l.allowEvent("player", EventMessageSend, now).muted)
+	assert.False(t, l.allowEvent("player", EventMessageSend, now).muted)
+
+	v := l.allowEvent("player", EventMessageSend, now)
+	assert.True(t, v.muted)
+	assert.Equal(t, time.Minute, v.retryAfter)
+
+	// Muted users can still read, but not chat, even once tokens are back
+	later := now.Add(30 * time.Second)
+	assert.True(t, l.allowEvent("player", EventMessageRead, later).allowed)
+	assert.True(t, l.allowEvent("player", EventMessageSend, later).muted)
+
+	assert.True(t, l.allowEvent("player", EventMessageSend, now.Add(time.Minute)).allowed)
+}
+
+func TestRateLimiterForgetsOldViolations(t *testing.T) {
+	l := newRateLimiter(testRateLimits())
+	now := time.Now()
+
+	for i := range 6 {
+		at := now.Add(time.Duration(i) * 6 * time.Second)
+		l.allowEvent("player", EventTyping, at)
+		assert.False(t, l.allowEvent("player", EventTyping, at).muted)
+	}
+}
+
+func TestRateLimiterSweepEvictsIdleEntries(t *testing.T) {
+	l := newRateLimiter(testRateLimits())
+	now := time.Now()
+
+	l.allowEvent("idle", EventMessageSend, now)
+	l.allowEvent("idle", EventTyping, now)
+	l.allowEvent("idle", EventTyping, now)
+	l.allowEvent("active", EventMessageSend, now.Add(50*time.Second))
+
+	l.sweep(now.Add(61 * time.Second))
+
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, limiterKey{userID: "active", bucket: EventMessageSend})
+	assert.Empty(t, l.offenders)
+}
+
+func TestRateLimiterThrottlesConnectionsWithoutMuting(t *testing.T) {
+	l := newRateLimiter(testRateLimits())
+	now := time.Now()
+
+	assert.True(t, l.allowConnection("player", now).allowed)
+	for range 5 {
+		assert.False(t, l.allowConnection("player", now).allowed)
+	}
+
+	assert.Empty(t, l.offenders)
+	assert.True(t, l.allowEvent("player", EventMessageSend, now).allowed)
+}
+
+func TestThrottledEventReportsRetryAfter(t *testing.T) {
+	m := NewManager(nil, nil)
+	c := newTestClient(m, "player")
+
+	c.throttled(Envelope{Type: EventMessageSend, ID: "req-9"}, verdict{retryAfter: 1500 * time.Millisecond})
+
+	got := <-c.send
+	assert.Equal(t, "req-9", got.ID)
+	payload := decodeTestError(t, got)
+	assert.Equal(t, ErrCodeRateLimited, payload.Code)
+	assert.Equal(t, int64(1500), payload.RetryAfterMs)
+}
@@ -0,0 +1,46 @@
+package websocket
+
+import (
+	"encoding/json"
+)
+
+// handleReactionAdd reacts to a message on behalf of the client and pushes the
+// updated counts to every participant.
+func (m *Manager) handleReactionAdd(c *Client, e Envelope) error {
+	req, err := decodeReaction(e)
+	if err != nil {
+		return err
+	}
+
+	change, err := m.actions.AddReaction(c.userID, req.MessageID, req.Emoji)
+	if err != nil {
+		return err
+	}
+
+	return m.publishUpdate(EventReactionAdded, change, "reaction saved but could not be delivered")
+}
+
+// handleReactionRemove takes back a reaction of the client and pushes the
+// updated counts to every participant.
+func (m *Manager) handleReactionRemove(c *Client, e Envelope) error {
+	req, err := decodeReaction(e)
+	if err != nil {
+		return err
+	}
+
+	change, err := m.actions.RemoveReaction(c.userID, req.MessageID, req.Emoji)
+	if err != nil {
+		return err
+	}
+
+	return m.publishUpdate(EventReactionRemoved, change, "reaction saved but could not be delivered")
+}
+
+func decodeReaction(e Envelope) (*ReactionPayload, error) {
+	var payload ReactionPayload
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return nil, NewHandlerError(ErrCodeInvalidPayload, "invalid reaction payload")
+	}
+
+	return &payload, nil
+}
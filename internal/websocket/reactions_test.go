@@ -0,0 +1,70 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testReactionChange mirrors the counts pushed after a reaction changes.
+type testReactionChange struct {
+	MessageID string              `json:"messageId"`
+	UserID    string              `json:"userId"`
+	Reactions []dto.ReactionCount `json:"reactions"`
+}
+
+func TestHandleReactionAddPushesCounts(t *testing.T) {
+	actions := new(MockMessageActions)
+	m := NewManager(nil, new(MockMessageRepository), WithMessageActions(actions))
+	receiver := newTestClient(m, "receiver")
+
+	id := uuid.NewString()
+	counts := []dto.ReactionCount{{Emoji: "🔥", Count: 1, UserIDs: []string{"receiver"}}}
+	actions.On("AddReaction", "receiver", id, "🔥").Return(&MessageUpdate{
+		Payload:      testReactionChange{MessageID: id, UserID: "receiver", Reactions: counts},
+		Participants: []string{"sender", "receiver"},
+		Changed:      true,
+	}, nil).Once()
+
+	m.dispatch(receiver, testEnvelope(t, EventReactionAdd, ReactionPayload{MessageID: id, Emoji: "🔥"}))
+
+	d := <-m.broadcast
+	assert.ElementsMatch(t, []string{"sender", "receiver"}, d.userIDs)
+	assert.Equal(t, EventReactionAdded, d.envelope.Type)
+
+	var change testReactionChange
+	assert.NoError(t, json.Unmarshal(d.envelope.Payload, &change))
+	assert.Equal(t, "receiver", change.UserID)
+	assert.Equal(t, counts, change.Reactions)
+	assert.Len(t, receiver.send, 0)
+	actions.AssertExpectations(t)
+}
+
+func TestHandleReactionAddTwiceIsSilent(t *testing.T) {
+	actions := new(MockMessageActions)
+	m := NewManager(nil, new(MockMessageRepository), WithMessageActions(actions))
+	receiver := newTestClient(m, "receiver")
+
+	id := uuid.NewString()
+	actions.On("AddReaction", "receiver", id, "🔥").Return(&MessageUpdate{Participants: []string{"sender", "receiver"}}, nil).Once()
+
+	m.dispatch(receiver, testEnvelope(t, EventReactionAdd, ReactionPayload{MessageID: id, Emoji: "🔥"}))
+
+	assert.Len(t, m.broadcast, 0)
+	assert.Len(t, receiver.send, 0)
+}
+
+func TestHandleReactionRejectsMalformedPayload(t *testing.T) {
+	actions := new(MockMessageActions)
+	m := NewManager(nil, new(MockMessageRepository), WithMessageActions(actions))
+	client := newTestClient(m, "player")
+
+	m.dispatch(client, Envelope{Version: ProtocolVersion, Type: EventReactionRemove, ID: "req-1", Payload: json.RawMessage(`"🔥"`)})
+
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-client.send).Code)
+	actions.AssertNotCalled(t, "RemoveReaction", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -1,53 +1,85 @@
 package websocket
 
 import (
-	"encoding/json"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
-	"github.com/mauFade/playzy/internal/constants"
-	"github.com/mauFade/playzy/internal/model"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/http/middleware"
 )
 
+// tokenSubprotocol is the Sec-WebSocket-Protocol entry browsers send before the
+// token itself ("bearer, <token>"), since they can't set headers on a handshake.
+const tokenSubprotocol = "bearer"
+
+// tokenFromRequest extracts the JWT from the Authorization header or, failing
+// that, from the Sec-WebSocket-Protocol header.
+func tokenFromRequest(r *http.Request) string {
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, protocol := range protocols {
+		if protocol == tokenSubprotocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+
+	return ""
+}
+
+// ServeWs upgrades an authenticated request to a websocket connection. The
+// optional since query parameter is the cursor of the last message the client
+// has; what was missed after it, along with one-to-one messages never
+// delivered, is replayed before live events.
 func (m *Manager) ServeWs(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("userID")
-	if userID == "" {
-		http.Error(w, "userID é obrigatório", http.StatusBadRequest)
+	tokenString := tokenFromRequest(r)
+	if tokenString == "" {
+		http.Error(w, "Authorization token missing", http.StatusUnauthorized)
 		return
 	}
 
-	// Rate limiting check
-	m.mutex.Lock()
-	if lastConnection, exists := m.rateLimiter[userID]; exists {
-		if time.Since(lastConnection) < time.Second {
-			m.mutex.Unlock()
-			http.Error(w, "Too many connection attempts", http.StatusTooManyRequests)
+	userID, expiresAt, err := middleware.ParseToken(tokenString)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// Clients resuming a session send the cursor of the last message they have
+	var since *dto.MessageCursor
+	if token := r.URL.Query().Get("since"); token != "" {
+		if since, err = dto.DecodeCursor(token); err != nil {
+			http.Error(w, "Invalid since cursor", http.StatusBadRequest)
 			return
 		}
 	}
-	m.rateLimiter[userID] = time.Now()
-	m.mutex.Unlock()
-
-	// Check for existing connection
-	m.mutex.Lock()
-	if existingClient, ok := m.clients[userID]; ok {
-		// Gracefully close existing connection
-		existingClient.conn.WriteControl(
-			websocket.CloseMessage,
-			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "New connection"),
-			time.Now().Add(time.Second),
-		)
-		existingClient.conn.Close()
-		delete(m.clients, userID)
+
+	// Rate limiting check
+	if v := m.limiter.allowConnection(userID, time.Now()); !v.allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(v.retryAfter.Seconds()))))
+		http.Error(w, "Too many connection attempts", http.StatusTooManyRequests)
+		return
 	}
-	m.mutex.Unlock()
+
+	// Each user may stay connected from a limited number of devices
+	if m.atConnectionLimit(userID) {
+		http.Error(w, "Too many open connections", http.StatusTooManyRequests)
+		return
+	}
+
+	connectionID := uuid.NewString()
 
 	// Upgrade connection with custom headers
 	header := http.Header{}
 	header.Add("X-User-ID", userID)
+	header.Add("X-Connection-ID", connectionID)
 
 	// Fazer upgrade da conexão HTTP para WebSocket
 	conn, err := upgrader.Upgrade(w, r, header)
@@ -58,12 +90,20 @@ func (m *Manager) ServeWs(w http.ResponseWriter, r *http.Request) {
 
 	// Create new client with enhanced configuration
 	client := &Client{
-		manager:  m,
-		conn:     conn,
-		send:     make(chan model.Message, 256),
-		userID:   userID,
-		isAlive:  true,
-		lastPing: time.Now(),
+		id:        connectionID,
+		manager:   m,
+		conn:      conn,
+		send:      make(chan Envelope, 256),
+		userID:    userID,
+		expiresAt: expiresAt,
+		isAlive:   true,
+		lastPing:  time.Now(),
+		catchUp:   m.repository != nil,
+		since:     since,
+	}
+
+	if client.catchUp {
+		client.holdLive()
 	}
 
 	// Register client
@@ -97,99 +137,3 @@ func (m *Manager) ServeWs(w http.ResponseWriter, r *http.Request) {
 		client.ReadPump()
 	}()
 }
-
-func (m *Manager) GetConversationHandler(w http.ResponseWriter, r *http.Request) {
-	// Obter ID do usuário do contexto
-	userID, ok := r.Context().Value(constants.UserKey).(string)
-	if !ok || userID == "" {
-		http.Error(w, "Usuário não autenticado", http.StatusUnauthorized)
-		return
-	}
-
-	// Obter ID do outro usuário
-	otherUserID := r.URL.Query().Get("otherUserId")
-	if otherUserID == "" {
-		http.Error(w, "otherUserId é obrigatório", http.StatusBadRequest)
-		return
-	}
-
-	// Obter limite de mensagens
-	limitStr := r.URL.Query().Get("limit")
-	limit := 50
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	// Obter offset (paginação)
-	offsetStr := r.URL.Query().Get("offset")
-	offset := 0
-	if offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
-	}
-
-	// Buscar mensagens do banco de dados
-	query := `
-			SELECT id, content, user_id, receiver_id, created_at, is_read
-			FROM messages
-			WHERE (user_id = $1 AND receiver_id = $2) OR (user_id = $2 AND receiver_id = $1)
-			ORDER BY created_at DESC
-			LIMIT $3 OFFSET $4
-	`
-
-	rows, err := m.db.Query(query, userID, otherUserID, limit, offset)
-	if err != nil {
-		http.Error(w, "Erro ao buscar mensagens", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	type MessageResponse struct {
-		ID         string    `json:"id"`
-		Content    string    `json:"content"`
-		SenderID   string    `json:"senderId"`
-		ReceiverID string    `json:"receiverId"`
-		Timestamp  time.Time `json:"timestamp"`
-		IsRead     bool      `json:"isRead"`
-		IsMine     bool      `json:"isMine"`
-	}
-
-	var messages []MessageResponse
-	for rows.Next() {
-		var msg MessageResponse
-		err := rows.Scan(&msg.ID, &msg.Content, &msg.SenderID, &msg.ReceiverID, &msg.Timestamp, &msg.IsRead)
-		if err != nil {
-			continue
-		}
-
-		// Marcar se a mensagem é do usuário atual
-		msg.IsMine = msg.SenderID == userID
-
-		messages = append(messages, msg)
-	}
-
-	// Inverter a ordem para mostrar as mais antigas primeiro
-	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
-		messages[i], messages[j] = messages[j], messages[i]
-	}
-
-	// Marcar mensagens como lidas (se o usuário for o destinatário)
-	if len(messages) > 0 {
-		_, err := m.db.Exec(`
-					UPDATE messages
-					SET is_read = true
-					WHERE receiver_id = $1 AND user_id = $2 AND is_read = false
-			`, userID, otherUserID)
-
-		if err != nil {
-			log.Printf("Erro ao marcar mensagens como lidas: %v", err)
-		}
-	}
-
-	// Enviar como JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(messages)
-}
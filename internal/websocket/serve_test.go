@@ -0,0 +1,105 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func signTestToken(t *testing.T, userID string, exp time.Time) string {
+	t.Helper()
+
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["userID"] = userID
+	claims["exp"] = exp.Unix()
+
+	tokenString, err := token.SignedString([]byte("test-secret"))
+	assert.NoError(t, err)
+
+	return tokenString
+}
+
+func startTestServer(t *testing.T) (*Manager, string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	m := NewManager(nil, nil)
+	go m.Start()
+
+	server := httptest.NewServer(http.HandlerFunc(m.ServeWs))
+	t.Cleanup(server.Close)
+
+	return m, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func dialHeader() http.Header {
+	header := http.Header{}
+	header.Set("Origin", "http://localhost:3000")
+	return header
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.Header.Set("Authorization", "Bearer header-token")
+	assert.Equal(t, "header-token", tokenFromRequest(r))
+
+	r = httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.Header.Set("Sec-WebSocket-Protocol", "bearer, protocol-token")
+	assert.Equal(t, "protocol-token", tokenFromRequest(r))
+
+	r = httptest.NewRequest(http.MethodGet, "/ws?userID=someone", nil)
+	assert.Equal(t, "", tokenFromRequest(r))
+}
+
+func TestServeWsRejectsMissingOrInvalidToken(t *testing.T) {
+	_, url := startTestServer(t)
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?userID=victim", dialHeader())
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	header := dialHeader()
+	header.Set("Authorization", "Bearer not-a-jwt")
+	_, resp, err = websocket.DefaultDialer.Dial(url, header)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestServeWsRegistersUserFromClaims(t *testing.T) {
+	m, url := startTestServer(t)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"bearer", signTestToken(t, "player-1", time.Now().Add(time.Hour))}}
+	conn, resp, err := dialer.Dial(url+"?userID=victim", dialHeader())
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, tokenSubprotocol, resp.Header.Get("Sec-WebSocket-Protocol"))
+
+	assert.Eventually(t, func() bool {
+		m.mutex.RLock()
+		defer m.mutex.RUnlock()
+		_, player := m.clients["player-1"]
+		_, victim := m.clients["victim"]
+		return player && !victim
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestServeWsClosesConnectionWhenTokenExpires(t *testing.T) {
+	_, url := startTestServer(t)
+
+	header := dialHeader()
+	header.Set("Authorization", "Bearer "+signTestToken(t, "player-1", time.Now().Add(2*time.Second)))
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "unexpected error: %v", err)
+}
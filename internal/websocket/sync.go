@@ -0,0 +1,248 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+)
+
+const (
+	// maxReplayBatch caps how many missed messages are replayed at once;
+	// clients ask for the rest with an EventSync.
+	maxReplayBatch = 100
+
+	// maxHeldEnvelopes is how many live envelopes may wait while a connection
+	// catches up. Together with a replay batch it must fit the send buffer.
+	maxHeldEnvelopes = 128
+)
+
+// catchUp replays what the user missed to a new connection and then lets the
+// live envelopes held meanwhile through.
+func (m *Manager) catchUp(c *Client, since *dto.MessageCursor) {
+	envelopes, err := m.replay(c.userID, since, "")
+	if err != nil {
+		log.Printf("Erro ao recuperar mensagens perdidas de %s: %v", c.userID, err)
+	}
+
+	if !c.releaseLive(envelopes) {
+		log.Printf("Conexão %s não conseguiu receber as mensagens perdidas", c.id)
+	}
+}
+
+// handleSync replays the messages the client missed after the given cursor.
+func (m *Manager) handleSync(c *Client, e Envelope) error {
+	var req SyncPayload
+	if len(e.Payload) > 0 {
+		if err := json.Unmarshal(e.Payload, &req); err != nil {
+			return NewHandlerError(ErrCodeInvalidPayload, "invalid sync payload")
+		}
+	}
+
+	var since *dto.MessageCursor
+	if req.Since != "" {
+		var err error
+		if since, err = dto.DecodeCursor(req.Since); err != nil {
+			return NewHandlerError(ErrCodeInvalidPayload, "invalid sync cursor")
+		}
+	}
+
+	envelopes, err := m.replay(c.userID, since, e.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, envelope := range envelopes {
+		if !c.enqueue(envelope) {
+			return NewHandlerError(ErrCodeServerBusy, "could not replay missed messages, retry later")
+		}
+	}
+
+	return nil
+}
+
+// replay loads a batch of the messages the user missed and returns them as
+// EventMessageNew envelopes followed by an EventSyncDone answering replyID.
+// One-to-one messages sent to the user are marked delivered.
+func (m *Manager) replay(userID string, since *dto.MessageCursor, replyID string) ([]Envelope, error) {
+	ms, err := m.repository.ListMissed(userID, since, maxReplayBatch+1)
+	if err != nil {
+		return nil, err
+	}
+
+	done := SyncDonePayload{HasMore: len(ms) > maxReplayBatch}
+	if done.HasMore {
+		ms = ms[:maxReplayBatch]
+	}
+
+	if since != nil {
+		done.Cursor = dto.EncodeCursor(*since)
+	}
+
+	if err := m.withAttachments(ms); err != nil {
+		return nil, err
+	}
+
+	envelopes := make([]Envelope, 0, len(ms)+1)
+	undelivered := []string{}
+
+	for _, msg := range ms {
+		envelope, err := NewEnvelope(EventMessageNew, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		envelopes = append(envelopes, envelope)
+
+		if msg.ReceiverID == userID && !msg.IsDelivered() {
+			undelivered = append(undelivered, msg.ID.String())
+		}
+	}
+
+	if len(ms) > 0 {
+		last := ms[len(ms)-1]
+		done.Cursor = dto.EncodeCursor(dto.MessageCursor{CreatedAt: last.Timestamp, ID: last.ID})
+	}
+	done.Count = len(ms)
+
+	if err := m.repository.MarkDelivered(userID, undelivered, time.Now()); err != nil {
+		log.Printf("Erro ao marcar mensagens como entregues: %v", err)
+	}
+
+	envelope, err := newReply(EventSyncDone, replyID, done)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(envelopes, envelope), nil
+}
+
+// withAttachments fills in the attachments of replayed messages.
+func (m *Manager) withAttachments(ms []model.Message) error {
+	if m.attachments == nil || len(ms) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(ms))
+	for _, msg := range ms {
+		ids = append(ids, msg.ID.String())
+	}
+
+	attachments, err := m.attachments.ListByMessages(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range ms {
+		ms[i].Attachments = attachments[ms[i].ID.String()]
+	}
+
+	return nil
+}
+
+// deliveredTo returns the receiver and ID of the one-to-one message carried by
+// an EventMessageNew delivery when this instance handed it to one of the
+// receiver's connections and it isn't marked delivered yet. Offline receivers
+// get the message replayed when they reconnect. The caller must hold m.mutex.
+func (m *Manager) deliveredTo(d delivery) (receiverID, messageID string, ok bool) {
+	if d.envelope.Type != EventMessageNew {
+		return "", "", false
+	}
+
+	var msg struct {
+		ID          string     `json:"id"`
+		SenderID    string     `json:"senderId"`
+		ReceiverID  string     `json:"receiverId"`
+		DeliveredAt *time.Time `json:"deliveredAt"`
+	}
+	if err := json.Unmarshal(d.envelope.Payload, &msg); err != nil {
+		return "", "", false
+	}
+
+	if msg.ReceiverID == "" || msg.ReceiverID == msg.SenderID || msg.DeliveredAt != nil || len(m.clients[msg.ReceiverID]) == 0 {
+		return "", "", false
+	}
+
+	return msg.ReceiverID, msg.ID, true
+}
+
+// markDelivered records that a message reached its receiver. Every instance
+// the receiver is connected to runs it for the same message; the repository
+// only sets delivered_at while it is unset, so the first one wins and the
+// others change nothing.
+func (m *Manager) markDelivered(receiverID, messageID string) {
+	if m.repository == nil {
+		return
+	}
+
+	if err := m.repository.MarkDelivered(receiverID, []string{messageID}, time.Now()); err != nil {
+		log.Printf("Erro ao marcar mensagem %s como entregue: %v", messageID, err)
+	}
+}
+
+// holdLive makes the connection keep live envelopes aside until releaseLive.
+func (c *Client) holdLive() {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	c.holding = true
+}
+
+// releaseLive queues the replayed envelopes, then the live ones held since
+// holdLive. Live messages that were also replayed are sent only once. It
+// returns false when the connection couldn't take them all.
+func (c *Client) releaseLive(replayed []Envelope) bool {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	held := c.held
+	c.holding = false
+	c.held = nil
+
+	if c.closed {
+		return false
+	}
+
+	seen := make(map[string]bool, len(replayed))
+
+	for _, e := range replayed {
+		if id := messageIDOf(e); id != "" {
+			seen[id] = true
+		}
+	}
+
+	for _, e := range held {
+		if id := messageIDOf(e); id == "" || !seen[id] {
+			replayed = append(replayed, e)
+		}
+	}
+
+	for _, e := range replayed {
+		select {
+		case c.send <- e:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// messageIDOf returns the ID of the message carried by an EventMessageNew
+// envelope, or "" for any other event.
+func messageIDOf(e Envelope) string {
+	if e.Type != EventMessageNew {
+		return ""
+	}
+
+	var payload struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return ""
+	}
+
+	return payload.ID
+}
@@ -0,0 +1,165 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/mauFade/playzy/internal/dto"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func missedMessages(n int) []model.Message {
+	start := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	ms := make([]model.Message, n)
+
+	for i := range ms {
+		ms[i] = model.Message{ID: uuid.New(), Content: "gg", SenderID: "friend", ReceiverID: "player", Timestamp: start.Add(time.Duration(i) * time.Minute)}
+	}
+
+	return ms
+}
+
+func decodeTestSyncDone(t *testing.T, e Envelope) SyncDonePayload {
+	t.Helper()
+
+	assert.Equal(t, EventSyncDone, e.Type)
+
+	var payload SyncDonePayload
+	assert.NoError(t, json.Unmarshal(e.Payload, &payload))
+
+	return payload
+}
+
+func TestCatchUpReplaysMissedMessagesBeforeLiveOnes(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	c := newTestClient(m, "player")
+	c.send = make(chan Envelope, 16)
+
+	ms := missedMessages(2)
+	repo.On("ListMissed", "player", (*dto.MessageCursor)(nil), maxReplayBatch+1).Return(ms, nil).Once()
+	repo.On("MarkDelivered", "player", []string{ms[0].ID.String(), ms[1].ID.String()}, mock.Anything).Return(nil).Once()
+
+	c.holdLive()
+
+	// A live message arrives while catching up, one of them twice
+	live := model.Message{ID: uuid.New(), Content: "you there?", SenderID: "friend", ReceiverID: "player"}
+	assert.True(t, c.enqueue(testMessageDelivery(t, ms[1]).envelope))
+	assert.True(t, c.enqueue(testMessageDelivery(t, live).envelope))
+	assert.Len(t, c.send, 0)
+
+	m.catchUp(c, nil)
+
+	assert.Equal(t, ms[0].ID, decodeTestMessage(t, <-c.send).ID)
+	assert.Equal(t, ms[1].ID, decodeTestMessage(t, <-c.send).ID)
+
+	done := decodeTestSyncDone(t, <-c.send)
+	assert.Equal(t, 2, done.Count)
+	assert.False(t, done.HasMore)
+	cursor, err := dto.DecodeCursor(done.Cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, ms[1].ID, cursor.ID)
+
+	assert.Equal(t, live.ID, decodeTestMessage(t, <-c.send).ID)
+	assert.Len(t, c.send, 0)
+
+	// Live envelopes flow straight through afterwards
+	assert.True(t, c.enqueue(testMessageDelivery(t, live).envelope))
+	assert.Len(t, c.send, 1)
+	repo.AssertExpectations(t)
+}
+
+func TestHandleSyncReplaysAfterCursorInBatches(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	c := newTestClient(m, "player")
+	c.send = make(chan Envelope, maxReplayBatch+1)
+
+	ms := missedMessages(maxReplayBatch + 1)
+	since := dto.MessageCursor{CreatedAt: ms[0].Timestamp.Add(-time.Minute), ID: uuid.New()}
+	for i := range ms {
+		ms[i].MarkRead(time.Now())
+		delivered := time.Now()
+		ms[i].DeliveredAt = &delivered
+	}
+
+	repo.On("ListMissed", "player", &since, maxReplayBatch+1).Return(ms, nil).Once()
+	repo.On("MarkDelivered", "player", []string{}, mock.Anything).Return(nil).Once()
+
+	m.dispatch(c, testEnvelope(t, EventSync, SyncPayload{Since: dto.EncodeCursor(since)}))
+
+	assert.Len(t, c.send, maxReplayBatch+1)
+	for range maxReplayBatch {
+		<-c.send
+	}
+
+	got := <-c.send
+	assert.Equal(t, "req-1", got.ID)
+	done := decodeTestSyncDone(t, got)
+	assert.True(t, done.HasMore)
+	assert.Equal(t, maxReplayBatch, done.Count)
+	repo.AssertExpectations(t)
+}
+
+func TestHandleSyncRejectsInvalidCursor(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	c := newTestClient(m, "player")
+
+	m.dispatch(c, testEnvelope(t, EventSync, SyncPayload{Since: "garbage"}))
+
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-c.send).Code)
+	repo.AssertNotCalled(t, "ListMissed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMarkDeliveredToConnectedReceiver(t *testing.T) {
+	repo := new(MockMessageRepository)
+	m := NewManager(nil, repo)
+	receiver := newTestClient(m, "receiver")
+	m.clients["receiver"] = map[string]*Client{receiver.id: receiver}
+
+	message := model.Message{ID: uuid.New(), Content: "gg", SenderID: "sender", ReceiverID: "receiver"}
+	repo.On("MarkDelivered", "receiver", []string{message.ID.String()}, mock.Anything).Return(nil).Once()
+
+	receiverID, messageID, ok := m.deliveredTo(testMessageDelivery(t, message))
+	assert.True(t, ok)
+
+	m.markDelivered(receiverID, messageID)
+
+	repo.AssertExpectations(t)
+}
+
+func TestMarkDeliveredSkipsReceiverConnectedElsewhere(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	_, _, ok := m.deliveredTo(testMessageDelivery(t, model.Message{ID: uuid.New(), Content: "gg", SenderID: "sender", ReceiverID: "receiver"}))
+
+	assert.False(t, ok)
+}
+
+func TestMarkDeliveredSkipsDeliveredMessages(t *testing.T) {
+	m := NewManager(nil, nil)
+	receiver := newTestClient(m, "receiver")
+	m.clients["receiver"] = map[string]*Client{receiver.id: receiver}
+
+	at := time.Now()
+	_, _, ok := m.deliveredTo(testMessageDelivery(t, model.Message{ID: uuid.New(), Content: "gg", SenderID: "sender", ReceiverID: "receiver", DeliveredAt: &at}))
+
+	assert.False(t, ok)
+}
+
+func TestServeWsRejectsInvalidSinceCursor(t *testing.T) {
+	_, url := startTestServer(t)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"bearer", signTestToken(t, "player-1", time.Now().Add(time.Hour))}}
+	_, resp, err := dialer.Dial(url+"?since=garbage", dialHeader())
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
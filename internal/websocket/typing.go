@@ -0,0 +1,165 @@
+package websocket
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// How long a typing indicator lasts unless the client refreshes it.
+	defaultTypingTimeout = 5 * time.Second
+
+	// Minimum interval between typing starts relayed for one connection.
+	typingThrottle = time.Second
+)
+
+// typingKey identifies a typing indicator from one user to another, or to a
+// group conversation.
+type typingKey struct {
+	from           string
+	to             string
+	conversationID string
+}
+
+// handleTyping relays a typing start or stop to the conversation partner, or
+// to the other members of a group, once the conversation is known to exist.
+// Starts are throttled per connection and expire on their own after
+// typingTimeout unless refreshed; nothing is persisted.
+func (m *Manager) handleTyping(c *Client, e Envelope) error {
+	var req TypingPayload
+	if err := json.Unmarshal(e.Payload, &req); err != nil {
+		return NewHandlerError(ErrCodeInvalidPayload, "invalid typing payload")
+	}
+
+	if req.State != TypingStart && req.State != TypingStop {
+		return NewHandlerError(ErrCodeInvalidPayload, "typing state must be start or stop")
+	}
+
+	now := time.Now()
+	if req.State == TypingStart && now.Sub(c.lastTyping) < typingThrottle {
+		// Drop silently; the indicator is still running on the other side.
+		return nil
+	}
+
+	key, recipients, err := m.typingRecipients(c, req)
+	if err != nil {
+		return err
+	}
+
+	if req.State == TypingStart {
+		c.lastTyping = now
+
+		m.startTyping(key, recipients)
+	} else {
+		c.lastTyping = time.Time{}
+
+		m.stopTyping(key, recipients)
+	}
+
+	return nil
+}
+
+// typingRecipients returns who a typing indicator goes to: the partner of an
+// existing direct conversation, or the other members of a group the typist
+// belongs to.
+func (m *Manager) typingRecipients(c *Client, req TypingPayload) (typingKey, []string, error) {
+	if m.conversations == nil {
+		return typingKey{}, nil, NewHandlerError(ErrCodeNotFound, "conversation not found")
+	}
+
+	if req.ConversationID == "" {
+		if _, err := uuid.Parse(req.To); err != nil || req.To == c.userID {
+			return typingKey{}, nil, NewHandlerError(ErrCodeInvalidPayload, "invalid receiver ID")
+		}
+
+		conversation, err := m.conversations.FindDirect(c.userID, req.To)
+		if err != nil {
+			return typingKey{}, nil, err
+		}
+
+		if conversation == nil {
+			return typingKey{}, nil, NewHandlerError(ErrCodeNotFound, "conversation not found")
+		}
+
+		return typingKey{from: c.userID, to: req.To}, []string{req.To}, nil
+	}
+
+	if _, err := uuid.Parse(req.ConversationID); err != nil {
+		return typingKey{}, nil, NewHandlerError(ErrCodeInvalidPayload, "invalid conversation ID")
+	}
+
+	members, err := m.conversations.ListMemberIDs(req.ConversationID)
+	if err != nil {
+		return typingKey{}, nil, err
+	}
+
+	if !slices.Contains(members, c.userID) {
+		return typingKey{}, nil, NewHandlerError(ErrCodeNotFound, "conversation not found")
+	}
+
+	others := slices.DeleteFunc(members, func(id string) bool { return id == c.userID })
+
+	return typingKey{from: c.userID, conversationID: req.ConversationID}, others, nil
+}
+
+// startTyping notifies the recipients and (re)arms the expiry timer.
+func (m *Manager) startTyping(key typingKey, recipients []string) {
+	m.typingMutex.Lock()
+	if timer, ok := m.typing[key]; ok {
+		timer.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(m.typingTimeout, func() {
+		m.typingMutex.Lock()
+		// A refresh may have replaced this timer after it fired.
+		expired := m.typing[key] == timer
+		if expired {
+			delete(m.typing, key)
+		}
+		m.typingMutex.Unlock()
+
+		if expired {
+			m.publishTyping(key, recipients, TypingStop)
+		}
+	})
+	m.typing[key] = timer
+	m.typingMutex.Unlock()
+
+	m.publishTyping(key, recipients, TypingStart)
+}
+
+// stopTyping notifies the recipients and cancels the expiry timer.
+func (m *Manager) stopTyping(key typingKey, recipients []string) {
+	m.typingMutex.Lock()
+	if timer, ok := m.typing[key]; ok {
+		timer.Stop()
+		delete(m.typing, key)
+	}
+	m.typingMutex.Unlock()
+
+	m.publishTyping(key, recipients, TypingStop)
+}
+
+// publishTyping sends the indicator to every connection of the recipients.
+// Indicators are best effort and are dropped when the manager is busy.
+func (m *Manager) publishTyping(key typingKey, recipients []string, state string) {
+	payload := TypingPayload{From: key.from, To: key.to, ConversationID: key.conversationID, State: state}
+	if state == TypingStart {
+		payload.ExpiresInMs = m.typingTimeout.Milliseconds()
+	}
+
+	envelope, err := NewEnvelope(EventTyping, payload)
+	if err != nil {
+		return
+	}
+
+	if m.saturated() {
+		return
+	}
+
+	m.publish(recipients, envelope)
+}
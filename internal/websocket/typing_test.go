@@ -0,0 +1,142 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauFade/playzy/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeTestTyping(t *testing.T, d delivery) TypingPayload {
+	t.Helper()
+
+	var payload TypingPayload
+	assert.Equal(t, EventTyping, d.envelope.Type)
+	assert.NoError(t, json.Unmarshal(d.envelope.Payload, &payload))
+
+	return payload
+}
+
+// newTypingTestManager returns a manager where typist and partner share a
+// direct conversation.
+func newTypingTestManager(t *testing.T) (m *Manager, typist *Client, partner string) {
+	t.Helper()
+
+	conversations := new(MockConversationRepository)
+	m = NewManager(nil, nil, WithConversationRepository(conversations))
+	typist = newTestClient(m, uuid.NewString())
+	partner = uuid.NewString()
+
+	conversations.On("FindDirect", typist.userID, partner).Return(&model.Conversation{ID: uuid.New(), Kind: model.ConversationDirect}, nil)
+
+	return m, typist, partner
+}
+
+func TestHandleTypingRoutesOnlyToPartner(t *testing.T) {
+	m, typist, partner := newTypingTestManager(t)
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{To: partner, State: TypingStart}))
+
+	d := <-m.broadcast
+	assert.Equal(t, []string{partner}, d.userIDs)
+	payload := decodeTestTyping(t, d)
+	assert.Equal(t, typist.userID, payload.From)
+	assert.Equal(t, TypingStart, payload.State)
+	assert.Positive(t, payload.ExpiresInMs)
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{To: partner, State: TypingStop}))
+
+	assert.Equal(t, TypingStop, decodeTestTyping(t, <-m.broadcast).State)
+}
+
+func TestHandleTypingThrottlesStartsPerConnection(t *testing.T) {
+	m, typist, partner := newTypingTestManager(t)
+
+	for i := 0; i < 10; i++ {
+		m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{To: partner, State: TypingStart}))
+	}
+
+	assert.Len(t, m.broadcast, 1)
+	assert.Len(t, typist.send, 0)
+}
+
+func TestHandleTypingExpiresWithoutRefresh(t *testing.T) {
+	m, typist, partner := newTypingTestManager(t)
+	m.typingTimeout = 20 * time.Millisecond
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{To: partner, State: TypingStart}))
+	assert.Equal(t, TypingStart, decodeTestTyping(t, <-m.broadcast).State)
+
+	select {
+	case d := <-m.broadcast:
+		assert.Equal(t, TypingStop, decodeTestTyping(t, d).State)
+	case <-time.After(time.Second):
+		t.Fatal("typing indicator did not expire")
+	}
+
+	m.typingMutex.Lock()
+	assert.Empty(t, m.typing)
+	m.typingMutex.Unlock()
+}
+
+func TestHandleTypingRejectsInvalidPayload(t *testing.T) {
+	m, typist, partner := newTypingTestManager(t)
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{State: TypingStart}))
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-typist.send).Code)
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{To: "partner", State: TypingStart}))
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-typist.send).Code)
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{To: partner, State: "dancing"}))
+	assert.Equal(t, ErrCodeInvalidPayload, decodeTestError(t, <-typist.send).Code)
+
+	assert.Len(t, m.broadcast, 0)
+}
+
+func TestHandleTypingRequiresExistingConversation(t *testing.T) {
+	conversations := new(MockConversationRepository)
+	m := NewManager(nil, nil, WithConversationRepository(conversations))
+	typist := newTestClient(m, uuid.NewString())
+	stranger := uuid.NewString()
+
+	conversations.On("FindDirect", typist.userID, stranger).Return((*model.Conversation)(nil), nil).Once()
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{To: stranger, State: TypingStart}))
+
+	assert.Equal(t, ErrCodeNotFound, decodeTestError(t, <-typist.send).Code)
+	assert.Len(t, m.broadcast, 0)
+	conversations.AssertExpectations(t)
+}
+
+func TestHandleTypingRoutesToGroupMembers(t *testing.T) {
+	conversations := new(MockConversationRepository)
+	m := NewManager(nil, nil, WithConversationRepository(conversations))
+	typist := newTestClient(m, "typist")
+	conversationID := uuid.NewString()
+
+	conversations.On("ListMemberIDs", conversationID).Return([]string{"duo", "typist", "trio"}, nil).Once()
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{ConversationID: conversationID, State: TypingStart}))
+
+	d := <-m.broadcast
+	assert.Equal(t, []string{"duo", "trio"}, d.userIDs)
+	assert.Equal(t, conversationID, decodeTestTyping(t, d).ConversationID)
+}
+
+func TestHandleTypingRejectsNonMembers(t *testing.T) {
+	conversations := new(MockConversationRepository)
+	m := NewManager(nil, nil, WithConversationRepository(conversations))
+	typist := newTestClient(m, "outsider")
+	conversationID := uuid.NewString()
+
+	conversations.On("ListMemberIDs", conversationID).Return([]string{"duo", "trio"}, nil).Once()
+
+	m.dispatch(typist, testEnvelope(t, EventTyping, TypingPayload{ConversationID: conversationID, State: TypingStart}))
+
+	assert.Equal(t, ErrCodeNotFound, decodeTestError(t, <-typist.send).Code)
+	assert.Len(t, m.broadcast, 0)
+}
@@ -1,6 +1,32 @@
+-- The API brings the schema up to date on startup (internal/repository/migrations.go);
+-- this file mirrors the resulting tables.
+
 -- users
-CREATE TABLE users (id UUID PRIMARY KEY, name VARCHAR NOT NULL, email VARCHAR NOT NULL, phone VARCHAR NOT NULL, password VARCHAR NOT NULL, gamertag VARCHAR NOT NULL, is_deleted BOOLEAN NOT NULL, deleted_at TIMESTAMP NULL, updated_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL);
+CREATE TABLE users (id UUID PRIMARY KEY, name VARCHAR NOT NULL, email VARCHAR NOT NULL, phone VARCHAR NOT NULL, password VARCHAR NOT NULL, gamertag VARCHAR NOT NULL, is_deleted BOOLEAN NOT NULL, deleted_at TIMESTAMP NULL, updated_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL, last_seen_at TIMESTAMP WITH TIME ZONE NULL);
 
 -- sessions
-CREATE TABLE sessions (id UUID PRIMARY KEY, game VARCHAR NOT NULL, user_id UUID NOT NULL, objective VARCHAR NOT NULL, rank VARCHAR NULL, is_ranked BOOLEAN NOT NULL, updated_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL, CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE);
+CREATE TABLE sessions (id UUID PRIMARY KEY, game VARCHAR NOT NULL, user_id UUID NOT NULL, objective VARCHAR NOT NULL, rank VARCHAR NULL, is_ranked BOOLEAN NOT NULL, updated_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL, max_players INTEGER NOT NULL DEFAULT 5, status VARCHAR(16) NOT NULL DEFAULT 'open', starts_at TIMESTAMP WITH TIME ZONE NULL, time_zone VARCHAR NOT NULL DEFAULT 'UTC', CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE);
+
+-- session_members
+CREATE TABLE session_members (session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE, user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, status VARCHAR(16) NOT NULL, requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(), responded_at TIMESTAMP WITH TIME ZONE NULL, PRIMARY KEY (session_id, user_id));
+
+-- conversations
+CREATE TABLE conversations (id UUID PRIMARY KEY DEFAULT gen_random_uuid(), kind VARCHAR NOT NULL, name VARCHAR NULL, direct_key VARCHAR NULL UNIQUE, created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(), session_id UUID NULL REFERENCES sessions(id) ON DELETE SET NULL, archived_at TIMESTAMP WITH TIME ZONE NULL);
+
+-- conversation_members
+CREATE TABLE conversation_members (conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE, user_id UUID NOT NULL REFERENCES users(id), joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(), PRIMARY KEY (conversation_id, user_id));
+
+-- messages
+CREATE TABLE messages (id UUID PRIMARY KEY DEFAULT gen_random_uuid(), content TEXT NOT NULL, user_id UUID NOT NULL REFERENCES users(id), receiver_id UUID NULL REFERENCES users(id), created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(), is_read BOOLEAN DEFAULT false, client_id VARCHAR NULL, read_at TIMESTAMP WITH TIME ZONE NULL, edited_at TIMESTAMP WITH TIME ZONE NULL, deleted_at TIMESTAMP WITH TIME ZONE NULL, reply_to_id UUID NULL REFERENCES messages(id), search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED, delivered_at TIMESTAMP WITH TIME ZONE NULL, conversation_id UUID NULL REFERENCES conversations(id));
+
+-- message_edits
+CREATE TABLE message_edits (id UUID PRIMARY KEY DEFAULT gen_random_uuid(), message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE, previous_content TEXT NOT NULL, edited_at TIMESTAMP WITH TIME ZONE NOT NULL);
+
+-- message_reactions
+CREATE TABLE message_reactions (message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE, user_id UUID NOT NULL REFERENCES users(id), emoji VARCHAR(32) NOT NULL, created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(), PRIMARY KEY (message_id, user_id, emoji));
+
+-- attachments
+CREATE TABLE attachments (id UUID PRIMARY KEY, uploader_id UUID NOT NULL REFERENCES users(id), message_id UUID NULL REFERENCES messages(id) ON DELETE CASCADE, file_name VARCHAR NOT NULL, content_type VARCHAR NOT NULL, size BIGINT NOT NULL, width INTEGER NOT NULL DEFAULT 0, height INTEGER NOT NULL DEFAULT 0, storage_key VARCHAR NOT NULL, thumbnail_key VARCHAR NULL, created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW());
 
+-- websocket_outbox
+CREATE TABLE websocket_outbox (id UUID PRIMARY KEY, payload TEXT NOT NULL, created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW());